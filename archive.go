@@ -0,0 +1,168 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// archiveSeparator separates archive path from member path, e.g. bundle.zip!/docs/readme.txt.
+const archiveSeparator = "!/"
+
+// tArchiveMember is a regular file in an archive.
+type tArchiveMember struct {
+	// name is the slash separated path in archive
+	name   string
+	info   os.FileInfo
+	reader io.Reader
+}
+
+func isArchive(name string) bool {
+	nameLower := strings.ToLower(name)
+	return strings.HasSuffix(nameLower, ".zip") || isTar(nameLower) || isTarGZ(nameLower)
+}
+
+func isTar(nameLower string) bool {
+	return strings.HasSuffix(nameLower, ".tar")
+}
+
+func isTarGZ(nameLower string) bool {
+	return strings.HasSuffix(nameLower, ".tar.gz") || strings.HasSuffix(nameLower, ".tgz")
+}
+
+// iterateArchive calls fn for every regular file in archive. Member reader is
+// valid only during call of fn. Errors of single members are passed to fn.
+func iterateArchive(archivePath string, fn func(index int, member *tArchiveMember, err error) error) error {
+	nameLower := strings.ToLower(archivePath)
+	if isTar(nameLower) || isTarGZ(nameLower) {
+		return iterateTar(archivePath, isTarGZ(nameLower), fn)
+	}
+	return iterateZip(archivePath, fn)
+}
+
+func iterateZip(archivePath string, fn func(index int, member *tArchiveMember, err error) error) error {
+	zipReader, err := zip.OpenReader(archivePath)
+	if err == nil {
+		defer zipReader.Close()
+		for i := 0; i < len(zipReader.File) && err == nil; i++ {
+			file := zipReader.File[i]
+			if file.Mode().IsRegular() {
+				var member tArchiveMember
+				var errMember error
+				member.name, errMember = memberName(archivePath, file.Name)
+				if errMember == nil {
+					var reader io.ReadCloser
+					reader, errMember = file.Open()
+					if errMember == nil {
+						member.info = file.FileInfo()
+						member.reader = reader
+						err = fn(i, &member, nil)
+						reader.Close()
+					}
+				}
+				if errMember != nil {
+					err = fn(i, &member, errMember)
+				}
+			}
+		}
+	}
+	return err
+}
+
+func iterateTar(archivePath string, compressed bool, fn func(index int, member *tArchiveMember, err error) error) error {
+	file, err := os.Open(archivePath)
+	if err == nil {
+		var reader io.Reader = file
+		defer file.Close()
+		if compressed {
+			var gzipReader *gzip.Reader
+			gzipReader, err = gzip.NewReader(file)
+			if err == nil {
+				defer gzipReader.Close()
+				reader = gzipReader
+			}
+		}
+		if err == nil {
+			tarReader := tar.NewReader(reader)
+			for i := 0; err == nil; i++ {
+				var header *tar.Header
+				header, err = tarReader.Next()
+				if err == nil && header.Typeflag == tar.TypeReg {
+					var member tArchiveMember
+					var errMember error
+					member.name, errMember = memberName(archivePath, header.Name)
+					if errMember == nil {
+						member.info = header.FileInfo()
+						member.reader = tarReader
+					}
+					err = fn(i, &member, errMember)
+				}
+			}
+			if err == io.EOF {
+				err = nil
+			}
+		}
+	}
+	return err
+}
+
+// memberName returns cleaned member path. Paths leaving the archive are refused.
+func memberName(archivePath, name string) (string, error) {
+	nameCleaned := path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	if path.IsAbs(nameCleaned) || nameCleaned == ".." || strings.HasPrefix(nameCleaned, "../") {
+		return "", errors.New("unsafe path in archive: " + archivePath + archiveSeparator + name)
+	}
+	return nameCleaned, nil
+}
+
+// processArchive filters members of archive. If action is not nil, it is
+// called for every matching member in a second pass over the archive with
+// the found filter terms.
+func (proc *tFileProcessorDefault) processArchive(archivePath string, action func(member *tArchiveMember, found []bool) error) error {
+	var matches []int
+	var matchesFound [][]bool
+	archiveRelPath := archivePath[proc.inputDirLength:]
+	err := iterateArchive(archivePath, func(index int, member *tArchiveMember, err error) error {
+		var match bool
+		var found []bool
+		if err == nil && proc.isFileMatch(archivePath+"/"+member.name, member.info) {
+			match, found, err = proc.isReaderMatch(member.reader)
+			if err != nil {
+				err = errors.New(archiveRelPath + archiveSeparator + member.name + ": " + err.Error())
+			}
+		}
+		if match && action != nil {
+			matches = append(matches, index)
+			matchesFound = append(matchesFound, found)
+		} else {
+			if match {
+				proc.printFile(archivePath+archiveSeparator+member.name, member.info, found, "match", "")
+			}
+			proc.postProcess(match, err)
+		}
+		return nil
+	})
+	if err == nil && len(matches) > 0 {
+		err = iterateArchive(archivePath, func(index int, member *tArchiveMember, err error) error {
+			if err == nil && len(matches) > 0 && matches[0] == index {
+				found := matchesFound[0]
+				matches, matchesFound = matches[1:], matchesFound[1:]
+				proc.postProcess(true, action(member, found))
+			}
+			return nil
+		})
+	}
+	return proc.postProcess(false, err)
+}
@@ -0,0 +1,30 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"testing"
+)
+
+func TestMemberName(t *testing.T) {
+	name, err := memberName("a.zip", "docs/./readme.txt")
+	if err != nil {
+		t.Error(err.Error())
+	} else if name != "docs/readme.txt" {
+		t.Error(name)
+	}
+	for _, unsafeName := range []string{"../x", "docs/../../x", "/etc/passwd", "..\\x"} {
+		_, err = memberName("a.zip", unsafeName)
+		if err == nil {
+			t.Error("unsafe path not recognized:", unsafeName)
+		}
+	}
+	if !isArchive("A.TAR.GZ") || !isArchive("b.zip") || isArchive("c.gz") {
+		t.Error("archive not recognized")
+	}
+}
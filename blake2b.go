@@ -0,0 +1,134 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	blake2bBlockSize = 128
+	blake2bSize      = 64
+)
+
+var blake2bIV = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179}
+
+var blake2bSigma = [12][16]byte{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3}}
+
+// tBLAKE2b is BLAKE2b-512 without key (RFC 7693), i.e. the checksum of b2sum.
+type tBLAKE2b struct {
+	h      [8]uint64
+	count  uint64
+	block  [blake2bBlockSize]byte
+	offset int
+}
+
+func newBLAKE2b() *tBLAKE2b {
+	digest := new(tBLAKE2b)
+	digest.Reset()
+	return digest
+}
+
+func (digest *tBLAKE2b) Reset() {
+	digest.h = blake2bIV
+	digest.h[0] ^= 0x01010000 ^ blake2bSize
+	digest.count = 0
+	digest.offset = 0
+}
+
+func (digest *tBLAKE2b) Size() int {
+	return blake2bSize
+}
+
+func (digest *tBLAKE2b) BlockSize() int {
+	return blake2bBlockSize
+}
+
+func (digest *tBLAKE2b) Write(data []byte) (int, error) {
+	length := len(data)
+	for len(data) > 0 {
+		// last block is compressed in Sum
+		if digest.offset == blake2bBlockSize {
+			digest.count += blake2bBlockSize
+			digest.compress(false)
+			digest.offset = 0
+		}
+		n := copy(digest.block[digest.offset:], data)
+		digest.offset += n
+		data = data[n:]
+	}
+	return length, nil
+}
+
+func (digest *tBLAKE2b) Sum(b []byte) []byte {
+	final := *digest
+	final.count += uint64(final.offset)
+	for i := final.offset; i < blake2bBlockSize; i++ {
+		final.block[i] = 0
+	}
+	final.compress(true)
+	var sum [blake2bSize]byte
+	for i, h := range final.h {
+		binary.LittleEndian.PutUint64(sum[i*8:], h)
+	}
+	return append(b, sum[:]...)
+}
+
+func (digest *tBLAKE2b) compress(last bool) {
+	var m [16]uint64
+	var v [16]uint64
+	for i := range m {
+		m[i] = binary.LittleEndian.Uint64(digest.block[i*8:])
+	}
+	copy(v[:8], digest.h[:])
+	copy(v[8:], blake2bIV[:])
+	// message length is less than 2^64 bytes, i.e. high word of counter is 0
+	v[12] ^= digest.count
+	if last {
+		v[14] = ^v[14]
+	}
+	for _, s := range blake2bSigma {
+		blake2bMix(&v, 0, 4, 8, 12, m[s[0]], m[s[1]])
+		blake2bMix(&v, 1, 5, 9, 13, m[s[2]], m[s[3]])
+		blake2bMix(&v, 2, 6, 10, 14, m[s[4]], m[s[5]])
+		blake2bMix(&v, 3, 7, 11, 15, m[s[6]], m[s[7]])
+		blake2bMix(&v, 0, 5, 10, 15, m[s[8]], m[s[9]])
+		blake2bMix(&v, 1, 6, 11, 12, m[s[10]], m[s[11]])
+		blake2bMix(&v, 2, 7, 8, 13, m[s[12]], m[s[13]])
+		blake2bMix(&v, 3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+	for i := range digest.h {
+		digest.h[i] ^= v[i] ^ v[i+8]
+	}
+}
+
+func blake2bMix(v *[16]uint64, a, b, c, d int, x, y uint64) {
+	v[a] = v[a] + v[b] + x
+	v[d] = bits.RotateLeft64(v[d]^v[a], -32)
+	v[c] = v[c] + v[d]
+	v[b] = bits.RotateLeft64(v[b]^v[c], -24)
+	v[a] = v[a] + v[b] + y
+	v[d] = bits.RotateLeft64(v[d]^v[a], -16)
+	v[c] = v[c] + v[d]
+	v[b] = bits.RotateLeft64(v[b]^v[c], -63)
+}
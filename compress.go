@@ -0,0 +1,91 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os/exec"
+)
+
+var (
+	magicGZIP  = []byte{0x1F, 0x8B}
+	magicBZIP2 = []byte{'B', 'Z', 'h'}
+	magicXZ    = []byte{0xFD, '7', 'z', 'X', 'Z', 0x00}
+	magicZSTD  = []byte{0x28, 0xB5, 0x2F, 0xFD}
+)
+
+// tExternalReader reads output of an external decompression program.
+type tExternalReader struct {
+	stdout  io.Reader
+	cmd     *exec.Cmd
+	program string
+	waited  bool
+}
+
+// newDecompressingReader returns reader, that decompresses gzip, bzip2, xz or zstd
+// content. Format is recognized by magic bytes; other content is returned unchanged.
+// xz and zstd need the programs xz and zstd to be installed.
+func newDecompressingReader(reader io.Reader) (io.ReadCloser, error) {
+	bufReader := bufio.NewReader(reader)
+	magic, _ := bufReader.Peek(len(magicXZ))
+	if bytes.HasPrefix(magic, magicGZIP) {
+		return gzip.NewReader(bufReader)
+	} else if bytes.HasPrefix(magic, magicBZIP2) {
+		return io.NopCloser(bzip2.NewReader(bufReader)), nil
+	} else if bytes.HasPrefix(magic, magicXZ) {
+		return newExternalReader(bufReader, "xz")
+	} else if bytes.HasPrefix(magic, magicZSTD) {
+		return newExternalReader(bufReader, "zstd")
+	}
+	return io.NopCloser(bufReader), nil
+}
+
+func newExternalReader(reader io.Reader, program string) (io.ReadCloser, error) {
+	path, err := exec.LookPath(program)
+	if err == nil {
+		extReader := new(tExternalReader)
+		extReader.cmd = exec.Command(path, "-d", "-c")
+		extReader.cmd.Stdin = reader
+		extReader.program = program
+		extReader.stdout, err = extReader.cmd.StdoutPipe()
+		if err == nil {
+			err = extReader.cmd.Start()
+			if err == nil {
+				return extReader, nil
+			}
+		}
+	}
+	return nil, errors.New("can't decompress " + program + " content: " + err.Error())
+}
+
+func (extReader *tExternalReader) Read(p []byte) (int, error) {
+	n, err := extReader.stdout.Read(p)
+	if err == io.EOF && !extReader.waited {
+		extReader.waited = true
+		errWait := extReader.cmd.Wait()
+		if errWait != nil {
+			err = errors.New(extReader.program + ": " + errWait.Error())
+		}
+	}
+	return n, err
+}
+
+// Close stops the program, if it is still running.
+func (extReader *tExternalReader) Close() error {
+	if !extReader.waited {
+		extReader.waited = true
+		extReader.cmd.Process.Kill()
+		extReader.cmd.Wait()
+	}
+	return nil
+}
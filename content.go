@@ -0,0 +1,368 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"regexp"
+	"regexp/syntax"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+const bufferSize = 1024 * 1024 * 4
+
+// bufferPool provides read buffers, if files are processed in threads.
+var bufferPool = sync.Pool{New: func() interface{} {
+	buffer := make([]byte, bufferSize)
+	return &buffer
+}}
+
+// tContentFilter matches file content against filter terms.
+type tContentFilter struct {
+	terms []tTerm
+	// names are the terms as given in arguments
+	names      []string
+	expr       *tExpr
+	overlap    int
+	encoding   int
+	decompress bool
+	// allTerms is true, if content is read until all terms are found, not
+	// only until the filter expression is decided
+	allTerms bool
+}
+
+// tReadCloser reads from Reader and closes Closer.
+type tReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// tTerm is a literal or a regular expression.
+type tTerm struct {
+	literal []byte
+	regex   *regexp.Regexp
+	// width is the maximum length of a match in bytes, or -1 if unbounded
+	width int
+}
+
+func newContentFilter(params *tParameters) (*tContentFilter, error) {
+	var err error
+	terms := params.contentFilter
+	filter := new(tContentFilter)
+	filter.decompress = params.decompress.Available()
+	// records list found terms
+	filter.allTerms = params.format.Available() && params.format.Values[0] != "text"
+	if params.encoding.Available() {
+		filter.encoding, err = parseEncoding(params.encoding.Values[0])
+		if err != nil {
+			return nil, err
+		}
+	}
+	if params.boolean.Available() {
+		filter.expr, terms, err = parseExpr(terms)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		filter.expr = newExprAll(len(terms), params.or.Available())
+	}
+	filter.names = terms
+	filter.terms = make([]tTerm, 0, len(terms))
+	for _, term := range terms {
+		var termCompiled tTerm
+		if params.ignoreCase.Available() {
+			// (?i) does Unicode simple case folding
+			if params.regex.Available() {
+				termCompiled, err = newRegexTerm("(?i)" + term)
+			} else {
+				termCompiled, err = newRegexTerm("(?i)" + regexp.QuoteMeta(term))
+			}
+		} else if params.regex.Available() {
+			termCompiled, err = newRegexTerm(term)
+		} else {
+			termCompiled = newLiteralTerm(term)
+		}
+		if err == nil && params.regex.Available() && hasAnchor(term) {
+			// content is read in chunks, beginning of chunk is not beginning of text, line or word
+			err = errors.New("anchors ^, $, \\A, \\z, \\b and \\B are not supported in filter terms: " + term)
+		}
+		if err == nil {
+			filter.terms = append(filter.terms, termCompiled)
+		} else {
+			return nil, err
+		}
+	}
+	filter.overlap = filter.maxWidth() - 1
+	return filter, nil
+}
+
+func newLiteralTerm(term string) tTerm {
+	return tTerm{literal: []byte(term), width: len(term)}
+}
+
+func newRegexTerm(term string) (tTerm, error) {
+	var termCompiled tTerm
+	regex, err := regexp.Compile(term)
+	if err == nil {
+		var regexSyntax *syntax.Regexp
+		regexSyntax, err = syntax.Parse(term, syntax.Perl)
+		if err == nil {
+			termCompiled.regex = regex
+			termCompiled.width = maxWidth(regexSyntax)
+			// e.g. a* or x? would match every file
+			if regex.Match(nil) {
+				err = errors.New("regular expression matches empty content: " + term)
+			}
+		}
+	}
+	if err != nil {
+		err = errors.New("wrong regular expression: " + err.Error())
+	}
+	return termCompiled, err
+}
+
+// hasAnchor returns true, if regular expression term contains ^, $, \A, \z, \b
+// or \B.
+func hasAnchor(term string) bool {
+	regex, err := syntax.Parse(term, syntax.Perl)
+	return err == nil && hasAnchorOp(regex)
+}
+
+func hasAnchorOp(regex *syntax.Regexp) bool {
+	switch regex.Op {
+	case syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return true
+	}
+	for _, sub := range regex.Sub {
+		if hasAnchorOp(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// index returns the position of the first match in data, or -1.
+func (term *tTerm) index(data []byte) int {
+	if term.regex == nil {
+		return bytes.Index(data, term.literal)
+	}
+	loc := term.regex.FindIndex(data)
+	if loc != nil {
+		return loc[0]
+	}
+	return -1
+}
+
+// maxWidth returns the maximum match length of all terms, or -1 if unbounded.
+func (filter *tContentFilter) maxWidth() int {
+	width := 0
+	for _, term := range filter.terms {
+		if term.width < 0 {
+			return -1
+		} else if term.width > width {
+			width = term.width
+		}
+	}
+	return width
+}
+
+// contentReader returns reader, that provides content as it is matched, i.e.
+// decompressed and decoded.
+func (filter *tContentFilter) contentReader(reader io.Reader) (io.ReadCloser, error) {
+	if filter.decompress {
+		decompReader, err := newDecompressingReader(reader)
+		if err == nil {
+			return &tReadCloser{newDecodingReader(decompReader, filter.encoding), decompReader}, nil
+		}
+		return nil, err
+	}
+	return io.NopCloser(newDecodingReader(reader, filter.encoding)), nil
+}
+
+// matchReader reads content in chunks of buffer size and stops as soon as
+// the filter expression is decided. The last bytes of a chunk
+// are kept for the next one, so matches crossing the chunk boundary are found, too.
+// Unbounded regular expressions are found, if they cross the boundary with
+// up to a quarter of buffer size.
+func (filter *tContentFilter) matchReader(reader io.Reader, buffer []byte) (bool, error) {
+	match, _, err := filter.findTerms(reader, buffer)
+	return match, err
+}
+
+// findTerms is like matchReader, but returns found terms, too. If allTerms is
+// set, matching content is read until all terms are found.
+func (filter *tContentFilter) findTerms(reader io.Reader, buffer []byte) (bool, []bool, error) {
+	var carry int
+	found := make([]bool, len(filter.terms))
+	overlap := filter.overlap
+	if overlap < 0 || overlap > len(buffer)/4 {
+		overlap = len(buffer) / 4
+	}
+	for {
+		n, err := io.ReadFull(reader, buffer[carry:])
+		if err == nil || err == io.EOF || err == io.ErrUnexpectedEOF {
+			window := buffer[:carry+n]
+			for i := range filter.terms {
+				if !found[i] && filter.terms[i].index(window) >= 0 {
+					found[i] = true
+				}
+			}
+			if match, known := filter.expr.eval(found, err != nil); known && (!match || !filter.allTerms || err != nil || allFound(found)) {
+				return match, found, nil
+			}
+			carry = overlap
+			copy(buffer, window[len(window)-carry:])
+		} else {
+			return false, nil, err
+		}
+	}
+}
+
+func allFound(found []bool) bool {
+	for _, termFound := range found {
+		if !termFound {
+			return false
+		}
+	}
+	return true
+}
+
+// groupCount returns the number of capture groups of all terms.
+func (filter *tContentFilter) groupCount() int {
+	var count int
+	for _, term := range filter.terms {
+		if term.regex != nil {
+			count += term.regex.NumSubexp()
+		}
+	}
+	return count
+}
+
+// findGroups returns capture groups of the first match of each term, numbered
+// in order of terms. Groups of terms without match are empty. Content is read
+// in chunks like in matchReader.
+func (filter *tContentFilter) findGroups(reader io.Reader, buffer []byte) ([]string, error) {
+	var carry int
+	groups := make([]string, filter.groupCount())
+	found := make([]bool, len(filter.terms))
+	missing := 0
+	for i, term := range filter.terms {
+		found[i] = term.regex == nil || term.regex.NumSubexp() == 0
+		if !found[i] {
+			missing++
+		}
+	}
+	overlap := filter.overlap
+	if overlap < 0 || overlap > len(buffer)/4 {
+		overlap = len(buffer) / 4
+	}
+	for missing > 0 {
+		n, err := io.ReadFull(reader, buffer[carry:])
+		if err == nil || err == io.EOF || err == io.ErrUnexpectedEOF {
+			window := buffer[:carry+n]
+			offset := 0
+			for i := range filter.terms {
+				term := &filter.terms[i]
+				if !found[i] {
+					if submatches := term.regex.FindSubmatch(window); submatches != nil {
+						for k, submatch := range submatches[1:] {
+							groups[offset+k] = string(submatch)
+						}
+						found[i] = true
+						missing--
+					}
+				}
+				if term.regex != nil {
+					offset += term.regex.NumSubexp()
+				}
+			}
+			if err != nil {
+				break
+			}
+			carry = overlap
+			copy(buffer, window[len(window)-carry:])
+		} else {
+			return nil, err
+		}
+	}
+	return groups, nil
+}
+
+// maxWidth returns the maximum number of bytes matched by regular expression, or -1 if unbounded.
+func maxWidth(regex *syntax.Regexp) int {
+	switch regex.Op {
+	case syntax.OpLiteral:
+		width := 0
+		for _, r := range regex.Rune {
+			width += runeWidth(r, regex.Flags&syntax.FoldCase != 0)
+		}
+		return width
+	case syntax.OpCharClass:
+		width := 0
+		for i := 1; i < len(regex.Rune); i += 2 {
+			if w := runeWidth(regex.Rune[i], false); w > width {
+				width = w
+			}
+		}
+		return width
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		return utf8.UTFMax
+	case syntax.OpCapture, syntax.OpQuest:
+		return maxWidth(regex.Sub[0])
+	case syntax.OpStar, syntax.OpPlus:
+		return -1
+	case syntax.OpRepeat:
+		width := maxWidth(regex.Sub[0])
+		if regex.Max >= 0 && width >= 0 {
+			return width * regex.Max
+		}
+		return -1
+	case syntax.OpConcat:
+		width := 0
+		for _, sub := range regex.Sub {
+			subWidth := maxWidth(sub)
+			if subWidth < 0 {
+				return -1
+			}
+			width += subWidth
+		}
+		return width
+	case syntax.OpAlternate:
+		width := 0
+		for _, sub := range regex.Sub {
+			subWidth := maxWidth(sub)
+			if subWidth < 0 {
+				return -1
+			} else if subWidth > width {
+				width = subWidth
+			}
+		}
+		return width
+	}
+	// empty matches, e.g. ^, $, \b
+	return 0
+}
+
+func runeWidth(r rune, foldCase bool) int {
+	width := utf8.RuneLen(r)
+	if width < 0 {
+		width = utf8.UTFMax
+	}
+	if foldCase {
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if w := utf8.RuneLen(f); w > width {
+				width = w
+			}
+		}
+	}
+	return width
+}
@@ -0,0 +1,177 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/vbsw/golib/osargs"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestContentFilterA(t *testing.T) {
+	buffer := make([]byte, 16)
+	content := "0123456789abcdefINV-123456xyz"
+	filter, err := newTestFilter("-x", "INV-[0-9]{6}")
+	if err == nil {
+		match, err := filter.matchReader(strings.NewReader(content), buffer)
+		if err != nil {
+			t.Error(err.Error())
+		} else if !match {
+			t.Error("regex not found")
+		}
+		// match crosses buffer boundary
+		match, err = filter.matchReader(strings.NewReader(content[10:]), buffer)
+		if err != nil {
+			t.Error(err.Error())
+		} else if !match {
+			t.Error("regex crossing buffer boundary not found")
+		}
+	} else {
+		t.Error(err.Error())
+	}
+	_, err = newTestFilter("-x", "a(b")
+	if err == nil {
+		t.Error("malformed regex not recognized")
+	}
+	// anchors would match at beginning of each chunk
+	for _, term := range []string{"^abc", "abc$", `\Aabc`, `abc\z`, "(?m)x|^y", `\bbob`, `a\Bb`} {
+		_, err = newTestFilter("-x", term)
+		if err == nil {
+			t.Error("anchor not recognized:", term)
+		}
+	}
+	_, err = newTestFilter("-x", `\^abc\$`)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	for _, term := range []string{"a*", "x?", "(a|)", "b{0,3}"} {
+		_, err = newTestFilter("-x", term)
+		if err == nil {
+			t.Error("empty match not recognized:", term)
+		}
+	}
+}
+
+func TestContentFilterB(t *testing.T) {
+	content := "alice, carol and bob"
+	if !isTestMatch(content, "alice", "bob") {
+		t.Error("AND filter failed")
+	}
+	if isTestMatch(content, "alice", "dave") {
+		t.Error("AND filter failed")
+	}
+	if !isTestMatch(content, "-x", "-o", "dave", "b.b") {
+		t.Error("OR filter failed")
+	}
+}
+
+func TestContentFilterC(t *testing.T) {
+	content := "alice, carol and bob"
+	if !isTestMatch(content, "-b", "(alice OR dave) AND NOT draft") {
+		t.Error("boolean filter failed")
+	}
+	if isTestMatch(content, "-b", "(alice", "OR", "dave)", "NOT", "carol") {
+		t.Error("boolean filter failed")
+	}
+	if !isTestMatch(content, "-b", "\"carol and\" NOT (dave OR draft)") {
+		t.Error("boolean filter with quoted term failed")
+	}
+	for _, expr := range []string{"(alice", "alice)", "alice AND", "OR bob", "\"alice"} {
+		_, err := newTestFilter("-b", expr)
+		if err == nil {
+			t.Error("malformed expression not recognized:", expr)
+		}
+	}
+}
+
+func TestContentFilterD(t *testing.T) {
+	content := "ÄLICE and Bob met at 4K"
+	if !isTestMatch(content, "-i", "älice", "BOB", "4k") {
+		t.Error("ignore case filter failed")
+	}
+	if !isTestMatch(content, "-i", "-x", "ä.ice") {
+		t.Error("ignore case regex filter failed")
+	}
+	if isTestMatch(content, "älice") {
+		t.Error("case sensitive filter failed")
+	}
+}
+
+func TestContentFilterE(t *testing.T) {
+	utf16LE := string([]byte{0xFF, 0xFE, 'a', 0, 'l', 0, 'i', 0, 'c', 0, 'e', 0, 0x3D, 0xD8, 0x00, 0xDE})
+	if !isTestMatch(utf16LE, "alice") {
+		t.Error("UTF-16LE content not decoded")
+	}
+	if !isTestMatch(utf16LE, "\U0001F600") {
+		t.Error("UTF-16LE surrogate pair not decoded")
+	}
+	utf16BE := string([]byte{'\x00', 'b', 0, 'o', 0, 'b'})
+	if !isTestMatch(utf16BE, "--encoding=utf-16be", "bob") {
+		t.Error("UTF-16BE content not decoded")
+	}
+	latin1 := string([]byte{'c', 'a', 'f', 0xE9, ' ', 0x80})
+	if !isTestMatch(latin1, "--encoding", "latin-1", "café") {
+		t.Error("Latin-1 content not decoded")
+	}
+	if !isTestMatch(latin1, "--encoding=windows-1252", "café €") {
+		t.Error("Windows-1252 content not decoded")
+	}
+	_, err := newTestFilter("--encoding=ebcdic", "bob")
+	if err == nil {
+		t.Error("unknown encoding not recognized")
+	}
+}
+
+func TestContentFilterF(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte("alice and bob"))
+	writer.Close()
+	filter, err := newTestFilter("-z", "bob")
+	if err == nil {
+		var reader io.ReadCloser
+		reader, err = filter.contentReader(&compressed)
+		if err == nil {
+			var match bool
+			match, err = filter.matchReader(reader, make([]byte, 64))
+			if err == nil && !match {
+				t.Error("gzip content not decompressed")
+			}
+			reader.Close()
+		}
+	}
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func newTestFilter(filter ...string) (*tContentFilter, error) {
+	args := new(osargs.Arguments)
+	args.Values = append([]string{"count", "."}, filter...)
+	args.Parsed = make([]bool, len(args.Values))
+	params := new(tParameters)
+	err := params.initFromArgs(args)
+	return params.filter, err
+}
+
+func isTestMatch(content string, filter ...string) bool {
+	contentFilter, err := newTestFilter(filter...)
+	if err == nil {
+		var match bool
+		var reader io.ReadCloser
+		reader, err = contentFilter.contentReader(strings.NewReader(content))
+		if err == nil {
+			match, err = contentFilter.matchReader(reader, make([]byte, 64))
+		}
+		return err == nil && match
+	}
+	return false
+}
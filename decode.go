@@ -0,0 +1,182 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const (
+	encAUTO = iota
+	encUTF8
+	encUTF16LE
+	encUTF16BE
+	encLATIN1
+	encWINDOWS1252
+)
+
+// windows1252 maps bytes 0x80 - 0x9F to runes. Undefined bytes map to C1 control characters.
+var windows1252 = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
+
+// tDecoder converts content to UTF-8.
+type tDecoder struct {
+	reader   io.Reader
+	encoding int
+	in       []byte
+	inLength int
+	out      []byte
+	outBegin int
+	err      error
+}
+
+func parseEncoding(name string) (int, error) {
+	switch strings.ToLower(name) {
+	case "auto":
+		return encAUTO, nil
+	case "utf-8", "utf8":
+		return encUTF8, nil
+	case "utf-16le", "utf16le":
+		return encUTF16LE, nil
+	case "utf-16be", "utf16be":
+		return encUTF16BE, nil
+	case "latin-1", "latin1", "iso-8859-1":
+		return encLATIN1, nil
+	case "windows-1252", "cp1252":
+		return encWINDOWS1252, nil
+	}
+	return encAUTO, errors.New("unknown encoding: " + name)
+}
+
+// newDecodingReader returns reader, that converts content to UTF-8. If encoding is
+// encAUTO, it is detected by byte order mark; without one content is returned unchanged.
+func newDecodingReader(reader io.Reader, encoding int) io.Reader {
+	if encoding == encAUTO || encoding == encUTF16LE || encoding == encUTF16BE {
+		bufReader := bufio.NewReader(reader)
+		bom, _ := bufReader.Peek(2)
+		if bytes.Equal(bom, []byte{0xFF, 0xFE}) && encoding != encUTF16BE {
+			bufReader.Discard(2)
+			encoding = encUTF16LE
+		} else if bytes.Equal(bom, []byte{0xFE, 0xFF}) && encoding != encUTF16LE {
+			bufReader.Discard(2)
+			encoding = encUTF16BE
+		}
+		reader = bufReader
+	}
+	if encoding != encAUTO && encoding != encUTF8 {
+		decoder := new(tDecoder)
+		decoder.reader = reader
+		decoder.encoding = encoding
+		decoder.in = make([]byte, 1024*16)
+		decoder.out = make([]byte, 0, len(decoder.in)*3)
+		return decoder
+	}
+	return reader
+}
+
+// isConverted returns true, if content of encoding name is converted to UTF-8.
+// Unknown names return false.
+func isConverted(name string) bool {
+	encoding, err := parseEncoding(name)
+	return err == nil && encoding != encAUTO && encoding != encUTF8
+}
+
+// hasUTF16BOM returns true, if data begins with byte order mark of UTF-16.
+func hasUTF16BOM(data []byte) bool {
+	return bytes.HasPrefix(data, []byte{0xFF, 0xFE}) || bytes.HasPrefix(data, []byte{0xFE, 0xFF})
+}
+
+func (decoder *tDecoder) Read(p []byte) (int, error) {
+	for decoder.outBegin == len(decoder.out) {
+		if decoder.err != nil {
+			return 0, decoder.err
+		}
+		var n int
+		n, decoder.err = decoder.reader.Read(decoder.in[decoder.inLength:])
+		decoder.inLength += n
+		decoder.outBegin = 0
+		decoder.out = decoder.out[:0]
+		decoder.decode(decoder.err != nil)
+	}
+	n := copy(p, decoder.out[decoder.outBegin:])
+	decoder.outBegin += n
+	return n, nil
+}
+
+// decode converts input to output. Incomplete characters at the end of input
+// are kept for the next call, unless final is true.
+func (decoder *tDecoder) decode(final bool) {
+	var i int
+	in := decoder.in[:decoder.inLength]
+	switch decoder.encoding {
+	case encUTF16LE, encUTF16BE:
+		for i+1 < len(in) {
+			r1 := decoder.utf16Unit(in[i:])
+			if utf16.IsSurrogate(r1) {
+				if i+3 < len(in) {
+					r2 := decoder.utf16Unit(in[i+2:])
+					if r := utf16.DecodeRune(r1, r2); r != utf8.RuneError {
+						decoder.appendRune(r)
+						i += 4
+					} else {
+						decoder.appendRune(utf8.RuneError)
+						i += 2
+					}
+				} else if final {
+					decoder.appendRune(utf8.RuneError)
+					i += 2
+				} else {
+					break
+				}
+			} else {
+				decoder.appendRune(r1)
+				i += 2
+			}
+		}
+		if final && i < len(in) {
+			decoder.appendRune(utf8.RuneError)
+			i = len(in)
+		}
+	case encLATIN1:
+		for ; i < len(in); i++ {
+			decoder.appendRune(rune(in[i]))
+		}
+	case encWINDOWS1252:
+		for ; i < len(in); i++ {
+			if b := in[i]; b >= 0x80 && b < 0xA0 {
+				decoder.appendRune(windows1252[b-0x80])
+			} else {
+				decoder.appendRune(rune(b))
+			}
+		}
+	}
+	decoder.inLength = copy(decoder.in, in[i:])
+}
+
+func (decoder *tDecoder) appendRune(r rune) {
+	var b [utf8.UTFMax]byte
+	n := utf8.EncodeRune(b[:], r)
+	decoder.out = append(decoder.out, b[:n]...)
+}
+
+func (decoder *tDecoder) utf16Unit(b []byte) rune {
+	if decoder.encoding == encUTF16LE {
+		return rune(b[0]) | rune(b[1])<<8
+	}
+	return rune(b[0])<<8 | rune(b[1])
+}
@@ -0,0 +1,195 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around changes.
+const diffContext = 3
+
+const (
+	diffEQUAL = iota
+	diffDELETE
+	diffINSERT
+)
+
+// unifiedDiff returns differences of lines of a and b in unified format (like
+// diff -u). Returns empty string, if a and b are equal.
+func unifiedDiff(nameA, nameB string, a, b []byte) string {
+	var diff strings.Builder
+	linesA, linesB := splitLines(a), splitLines(b)
+	ops := diffLines(linesA, linesB)
+	for begin := 0; begin < len(ops); {
+		// first change
+		for begin < len(ops) && ops[begin] == diffEQUAL {
+			begin++
+		}
+		if begin == len(ops) {
+			break
+		}
+		// last change of hunk, i.e. changes with less than two contexts between them
+		end, equal := begin, 0
+		for i := begin; i < len(ops) && equal <= diffContext*2; i++ {
+			if ops[i] == diffEQUAL {
+				equal++
+			} else {
+				end, equal = i+1, 0
+			}
+		}
+		hunkBegin, hunkEnd := maxInt(begin-diffContext, 0), minInt(end+diffContext, len(ops))
+		if diff.Len() == 0 {
+			diff.WriteString("--- " + nameA + "\n+++ " + nameB + "\n")
+		}
+		writeHunk(&diff, ops[:hunkEnd], hunkBegin, linesA, linesB)
+		begin = end
+	}
+	return diff.String()
+}
+
+// writeHunk writes lines of ops[begin:] as hunk. ops before begin are needed
+// for line numbers.
+func writeHunk(diff *strings.Builder, ops []int, begin int, linesA, linesB [][]byte) {
+	var indexA, indexB, countA, countB int
+	for _, op := range ops[:begin] {
+		if op != diffINSERT {
+			indexA++
+		}
+		if op != diffDELETE {
+			indexB++
+		}
+	}
+	for _, op := range ops[begin:] {
+		if op != diffINSERT {
+			countA++
+		}
+		if op != diffDELETE {
+			countB++
+		}
+	}
+	diff.WriteString("@@ -" + hunkRange(indexA, countA) + " +" + hunkRange(indexB, countB) + " @@\n")
+	for _, op := range ops[begin:] {
+		switch op {
+		case diffEQUAL:
+			writeDiffLine(diff, ' ', linesA[indexA])
+			indexA++
+			indexB++
+		case diffDELETE:
+			writeDiffLine(diff, '-', linesA[indexA])
+			indexA++
+		case diffINSERT:
+			writeDiffLine(diff, '+', linesB[indexB])
+			indexB++
+		}
+	}
+}
+
+func writeDiffLine(diff *strings.Builder, prefix byte, line []byte) {
+	diff.WriteByte(prefix)
+	diff.Write(line)
+	if !bytes.HasSuffix(line, []byte{'\n'}) {
+		diff.WriteString("\n\\ No newline at end of file\n")
+	}
+}
+
+// hunkRange returns line number and count of hunk. Line number of empty range
+// is the line before it.
+func hunkRange(index, count int) string {
+	if count == 1 {
+		return strconv.Itoa(index + 1)
+	} else if count == 0 {
+		return strconv.Itoa(index) + ",0"
+	}
+	return strconv.Itoa(index+1) + "," + strconv.Itoa(count)
+}
+
+// splitLines returns lines of data including their line breaks.
+func splitLines(data []byte) [][]byte {
+	lines := bytes.SplitAfter(data, []byte{'\n'})
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the shortest edit script transforming a into b (Myers'
+// algorithm). Each operation is diffEQUAL, diffDELETE or diffINSERT.
+func diffLines(a, b [][]byte) []int {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	// trace[d] is v[-d:d+1] before step d
+	var trace [][]int
+	var d int
+	for found := false; !found; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d && !found; k += 2 {
+			var x int
+			if k == -d || k != d && v[offset+k-1] < v[offset+k+1] {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && bytes.Equal(a[x], b[y]) {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			found = x >= n && y >= m
+		}
+	}
+	ops := make([]int, 0, n+m)
+	x, y := n, m
+	for d--; d > 0; d-- {
+		vd := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || k != d && vd[d+k-1] < vd[d+k+1] {
+			prevK = k + 1
+		}
+		prevX := vd[d+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			ops = append(ops, diffEQUAL)
+			x--
+			y--
+		}
+		if x == prevX {
+			ops = append(ops, diffINSERT)
+			y--
+		} else {
+			ops = append(ops, diffDELETE)
+			x--
+		}
+	}
+	for ; x > 0; x-- {
+		ops = append(ops, diffEQUAL)
+	}
+	for i, k := 0, len(ops)-1; i < k; i, k = i+1, k-1 {
+		ops[i], ops[k] = ops[k], ops[i]
+	}
+	return ops
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,268 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"errors"
+)
+
+const (
+	exprTERM = iota
+	exprAND
+	exprOR
+	exprNOT
+)
+
+const (
+	tokenTERM = iota
+	tokenAND
+	tokenOR
+	tokenNOT
+	tokenOPEN
+	tokenCLOSE
+)
+
+// tExpr is a node in a boolean filter expression.
+type tExpr struct {
+	op   int
+	term int
+	subs []*tExpr
+}
+
+type tToken struct {
+	kind  int
+	value string
+}
+
+type tExprParser struct {
+	tokens []tToken
+	pos    int
+	terms  []string
+}
+
+// newExprAll returns expression, that is true, if all terms are found (or any, if or is true).
+func newExprAll(termsCount int, or bool) *tExpr {
+	expr := new(tExpr)
+	if or {
+		expr.op = exprOR
+	} else {
+		expr.op = exprAND
+	}
+	expr.subs = make([]*tExpr, termsCount)
+	for i := range expr.subs {
+		expr.subs[i] = &tExpr{op: exprTERM, term: i}
+	}
+	return expr
+}
+
+// parseExpr parses boolean expression from filter arguments. Returns the expression
+// and its terms. Operators are AND, OR, NOT and parentheses. Terms separated only by
+// space are combined with AND. Terms in double quotes may contain spaces, parentheses
+// and operator names.
+func parseExpr(args []string) (*tExpr, []string, error) {
+	var parser tExprParser
+	for _, arg := range args {
+		tokens, err := tokenize(arg)
+		if err == nil {
+			parser.tokens = append(parser.tokens, tokens...)
+		} else {
+			return nil, nil, err
+		}
+	}
+	if len(parser.tokens) > 0 {
+		expr, err := parser.parseOr()
+		if err == nil && parser.pos < len(parser.tokens) {
+			err = errors.New("wrong filter expression: unexpected )")
+		}
+		return expr, parser.terms, err
+	}
+	return newExprAll(0, false), nil, nil
+}
+
+func tokenize(arg string) ([]tToken, error) {
+	var tokens []tToken
+	for i := 0; i < len(arg); {
+		switch b := arg[i]; b {
+		case ' ', '\t', '\n', '\r':
+			i++
+		case '(':
+			tokens = append(tokens, tToken{kind: tokenOPEN})
+			i++
+		case ')':
+			tokens = append(tokens, tToken{kind: tokenCLOSE})
+			i++
+		case '"':
+			term := make([]byte, 0, len(arg)-i)
+			closed := false
+			for i++; i < len(arg) && !closed; i++ {
+				if arg[i] == '"' {
+					closed = true
+				} else if arg[i] == '\\' && i+1 < len(arg) && (arg[i+1] == '"' || arg[i+1] == '\\') {
+					term = append(term, arg[i+1])
+					i++
+				} else {
+					term = append(term, arg[i])
+				}
+			}
+			if !closed {
+				return nil, errors.New("wrong filter expression: missing closing quote")
+			} else if len(term) == 0 {
+				return nil, errors.New("wrong filter expression: empty term")
+			}
+			tokens = append(tokens, tToken{kind: tokenTERM, value: string(term)})
+		default:
+			begin := i
+			for i < len(arg) && !isTokenDelimiter(arg[i]) {
+				i++
+			}
+			word := arg[begin:i]
+			switch word {
+			case "AND":
+				tokens = append(tokens, tToken{kind: tokenAND})
+			case "OR":
+				tokens = append(tokens, tToken{kind: tokenOR})
+			case "NOT":
+				tokens = append(tokens, tToken{kind: tokenNOT})
+			default:
+				tokens = append(tokens, tToken{kind: tokenTERM, value: word})
+			}
+		}
+	}
+	return tokens, nil
+}
+
+func isTokenDelimiter(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '(' || b == ')' || b == '"'
+}
+
+func (parser *tExprParser) parseOr() (*tExpr, error) {
+	expr, err := parser.parseAnd()
+	if err == nil && parser.peek(tokenOR) {
+		exprOr := &tExpr{op: exprOR, subs: []*tExpr{expr}}
+		for err == nil && parser.peek(tokenOR) {
+			parser.pos++
+			expr, err = parser.parseAnd()
+			exprOr.subs = append(exprOr.subs, expr)
+		}
+		return exprOr, err
+	}
+	return expr, err
+}
+
+func (parser *tExprParser) parseAnd() (*tExpr, error) {
+	expr, err := parser.parseNot()
+	if err == nil && parser.andFollows() {
+		exprAnd := &tExpr{op: exprAND, subs: []*tExpr{expr}}
+		for err == nil && parser.andFollows() {
+			if parser.peek(tokenAND) {
+				parser.pos++
+			}
+			expr, err = parser.parseNot()
+			exprAnd.subs = append(exprAnd.subs, expr)
+		}
+		return exprAnd, err
+	}
+	return expr, err
+}
+
+func (parser *tExprParser) parseNot() (*tExpr, error) {
+	if parser.peek(tokenNOT) {
+		parser.pos++
+		expr, err := parser.parseNot()
+		return &tExpr{op: exprNOT, subs: []*tExpr{expr}}, err
+	}
+	return parser.parsePrimary()
+}
+
+func (parser *tExprParser) parsePrimary() (*tExpr, error) {
+	if parser.pos < len(parser.tokens) {
+		token := parser.tokens[parser.pos]
+		parser.pos++
+		switch token.kind {
+		case tokenTERM:
+			parser.terms = append(parser.terms, token.value)
+			return &tExpr{op: exprTERM, term: len(parser.terms) - 1}, nil
+		case tokenOPEN:
+			expr, err := parser.parseOr()
+			if err == nil {
+				if parser.peek(tokenCLOSE) {
+					parser.pos++
+				} else {
+					err = errors.New("wrong filter expression: missing )")
+				}
+			}
+			return expr, err
+		case tokenCLOSE:
+			return nil, errors.New("wrong filter expression: unexpected )")
+		}
+		return nil, errors.New("wrong filter expression: term missing before operator")
+	}
+	return nil, errors.New("wrong filter expression: term missing at end")
+}
+
+func (parser *tExprParser) peek(kind int) bool {
+	return parser.pos < len(parser.tokens) && parser.tokens[parser.pos].kind == kind
+}
+
+// andFollows returns true, if next token is AND or starts an operand (implicit AND).
+func (parser *tExprParser) andFollows() bool {
+	return parser.peek(tokenAND) || parser.peek(tokenTERM) || parser.peek(tokenNOT) || parser.peek(tokenOPEN)
+}
+
+// eval returns the value of the expression and whether it is already known.
+// Terms not found yet are unknown, unless final is true; then they are false.
+func (expr *tExpr) eval(found []bool, final bool) (bool, bool) {
+	switch expr.op {
+	case exprTERM:
+		if found[expr.term] {
+			return true, true
+		}
+		return false, final
+	case exprNOT:
+		value, known := expr.subs[0].eval(found, final)
+		return !value, known
+	case exprAND:
+		return expr.evalSubs(found, final, false)
+	case exprOR:
+		return expr.evalSubs(found, final, true)
+	}
+	return false, true
+}
+
+// evalSubs evaluates AND (decisive is false) or OR (decisive is true).
+func (expr *tExpr) evalSubs(found []bool, final, decisive bool) (bool, bool) {
+	allKnown := true
+	for _, sub := range expr.subs {
+		value, known := sub.eval(found, final)
+		if known {
+			if value == decisive {
+				return decisive, true
+			}
+		} else {
+			allKnown = false
+		}
+	}
+	return !decisive, allKnown
+}
+
+// markPositive sets positive[term] to true for terms, that are not negated
+// by NOT, i.e. terms, that support the expression when found.
+func (expr *tExpr) markPositive(positive []bool, negated bool) {
+	switch expr.op {
+	case exprTERM:
+		if !negated {
+			positive[expr.term] = true
+		}
+	case exprNOT:
+		expr.subs[0].markPositive(positive, !negated)
+	default:
+		for _, sub := range expr.subs {
+			sub.markPositive(positive, negated)
+		}
+	}
+}
@@ -9,25 +9,35 @@
 package main
 
 import (
+	"bytes"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/vbsw/golib/check/v2"
 	"github.com/vbsw/golib/iter"
 	"github.com/vbsw/golib/osargs"
+	"hash"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 const (
-	argCOUNT = "count"
-	argCP    = "cp"
-	argMV    = "mv"
-	argPRINT = "print"
-	argRM    = "rm"
+	argCOUNT   = "count"
+	argCP      = "cp"
+	argMV      = "mv"
+	argPRINT   = "print"
+	argRENAME  = "rename"
+	argREPLACE = "replace"
+	argREPORT  = "report"
+	argRM      = "rm"
+	argUNDO    = "undo"
 )
 
 type tParameters struct {
@@ -36,6 +46,46 @@ type tParameters struct {
 	example        *osargs.Result
 	copyright      *osargs.Result
 	or             *osargs.Result
+	regex          *osargs.Result
+	boolean        *osargs.Result
+	ignoreCase     *osargs.Result
+	encoding       *osargs.Result
+	decompress     *osargs.Result
+	archives       *osargs.Result
+	include        *osargs.Result
+	exclude        *osargs.Result
+	includeDir     *osargs.Result
+	excludeDir     *osargs.Result
+	ignoreFiles    *osargs.Result
+	size           *osargs.Result
+	newer          *osargs.Result
+	older          *osargs.Result
+	perm           *osargs.Result
+	executable     *osargs.Result
+	dryRun         *osargs.Result
+	journal        *osargs.Result
+	trash          *osargs.Result
+	overwrite      *osargs.Result
+	rename         *osargs.Result
+	preserve       *osargs.Result
+	verify         *osargs.Result
+	manifest       *osargs.Result
+	flatten        *osargs.Result
+	destTemplate   *osargs.Result
+	to             *osargs.Result
+	find           *osargs.Result
+	with           *osargs.Result
+	backup         *osargs.Result
+	diff           *osargs.Result
+	lines          *osargs.Result
+	afterContext   *osargs.Result
+	beforeContext  *osargs.Result
+	context        *osargs.Result
+	color          *osargs.Result
+	format         *osargs.Result
+	print0         *osargs.Result
+	filesFrom      *osargs.Result
+	noTrash        *osargs.Result
 	silent         *osargs.Result
 	threads        *osargs.Result
 	command        *osargs.Result
@@ -44,10 +94,16 @@ type tParameters struct {
 	output         *osargs.Result
 	contentFilter  []string
 	fileNameFilter string
+	filter         *tContentFilter
+	nameFilter     *tNameFilter
+	metaFilter     *tMetaFilter
+	journalLog     *tJournal
+	trashBin       *tTrash
 }
 
 type tFileProcessor interface {
 	iter.FileProcessor
+	isDirMatch(path string, info os.FileInfo) bool
 	printSummary(err error)
 }
 
@@ -55,10 +111,13 @@ type tFileProcessorDefault struct {
 	count          int
 	inputDirLength int
 	silent         bool
-	or             bool
 	threads        bool
-	contentFilter  [][]byte
-	fileNameFilter []string
+	archives       bool
+	dryRun         bool
+	contentFilter  *tContentFilter
+	nameFilter     *tNameFilter
+	metaFilter     *tMetaFilter
+	journal        *tJournal
 	buffer         []byte
 	mutex          sync.Mutex
 }
@@ -71,6 +130,21 @@ type tFileProcessorCP struct {
 	tFileProcessorDefault
 	existingDirs []string
 	outputDir    string
+	// plannedFiles are targets in dry run and renamed targets
+	plannedFiles map[string]bool
+	overwrite    int
+	// conflictWarning is true, if no overwrite policy is set
+	conflictWarning bool
+	rename          bool
+	verb            string
+	stats           tTransferStats
+	preserve        int
+	inputDir        string
+	// createdDirs get metadata of input directories at the end
+	createdDirs []string
+	verifier    *tVerifier
+	// template is nil, if subdirectories of input directory are mirrored
+	template *tTemplate
 }
 
 type tFileProcessorMV struct {
@@ -79,33 +153,61 @@ type tFileProcessorMV struct {
 
 type tFileProcessorPrint struct {
 	tFileProcessorDefault
+	// linePrinter is nil, if only file names are printed
+	linePrinter *tLinePrinter
+}
+
+type tFileProcessorReport struct {
+	tFileProcessorDefault
+	totals []tTermTotal
+}
+
+type tFileProcessorRename struct {
+	tFileProcessorDefault
+	template *tTemplate
+	// groups is true, if template contains capture groups of content filter
+	groups  bool
+	renames []tRename
+}
+
+type tFileProcessorReplace struct {
+	tFileProcessorDefault
+	replacer *tReplacer
+	// backupSuffix is empty, if no backups are kept
+	backupSuffix string
+	diff         bool
+	preserve     int
+	changed      int
+	replacements int
 }
 
 type tFileProcessorRM struct {
 	tFileProcessorDefault
+	// trashDir receives removed files, if journal is written
+	trashDir string
+	// trash is nil in dry run
+	trash    *tTrash
+	useTrash bool
 }
 
 func main() {
 	var params tParameters
 	err := params.initFromOSArgs()
+	params.initOutput()
 	if err == nil {
 		if params.infoAvailable() {
 			printInfo(&params)
-		} else {
-			proc := newFileProcessor(&params)
-			if params.recursive.Available() {
-				if params.threads.Available() {
-					err = iter.IterateFilesRecrGo(params.inputDir(), proc)
-				} else {
-					err = iter.IterateFilesRecr(params.inputDir(), proc)
-				}
+		} else if params.command.Values[0] == argUNDO {
+			var count int
+			count, err = undoJournal(params.input.Values[0], params.dryRun.Available(), params.silent.Available())
+			if err == nil {
+				printFinished(count)
 			} else {
-				if params.threads.Available() {
-					err = iter.IterateFilesFlatGo(params.inputDir(), proc)
-				} else {
-					err = iter.IterateFilesFlat(params.inputDir(), proc)
-				}
+				printError(err)
 			}
+		} else {
+			proc := newFileProcessor(&params)
+			err = iterateFiles(&params, proc)
 			proc.printSummary(err)
 		}
 	} else {
@@ -119,10 +221,26 @@ func (params *tParameters) initFromOSArgs() error {
 	return err
 }
 
+// initOutput enables records, if format is jsonl or csv, and NUL separated
+// file names. Errors of arguments are written as records, too, if format is
+// valid.
+func (params *tParameters) initOutput() {
+	if params.format != nil && params.format.Available() {
+		records.format, _ = parseFormat(params.format.Values[0])
+		records.dryRun = params.dryRun != nil && params.dryRun.Available()
+	}
+	print0 = params.print0 != nil && params.print0.Available()
+}
+
 func (params *tParameters) inputDir() string {
 	return params.input.Values[0]
 }
 
+// isRecursive returns true, if recursive option is set or file name filter contains directories.
+func (params *tParameters) isRecursive() bool {
+	return params.recursive.Available() || strings.ContainsRune(params.fileNameFilter, '/')
+}
+
 func (params *tParameters) infoAvailable() bool {
 	if params.help == nil || !params.command.Available() {
 		return true
@@ -134,14 +252,57 @@ func (params *tParameters) infoAvailable() bool {
 func (params *tParameters) initFromArgs(args *osargs.Arguments) error {
 	var err error
 	if len(args.Values) > 0 {
+		delimiter := osargs.NewDelimiter(true, false, "=")
+		params.encoding = args.ParsePairs(delimiter, "--encoding", "-encoding")
+		params.includeDir = args.ParsePairs(delimiter, "--include-dir", "-include-dir")
+		params.excludeDir = args.ParsePairs(delimiter, "--exclude-dir", "-exclude-dir")
+		params.include = args.ParsePairs(delimiter, "--include", "-include")
+		params.exclude = args.ParsePairs(delimiter, "--exclude", "-exclude")
+		params.size = args.ParsePairs(delimiter, "--size", "-size")
+		params.newer = args.ParsePairs(delimiter, "--newer", "-newer")
+		params.older = args.ParsePairs(delimiter, "--older", "-older")
+		params.perm = args.ParsePairs(delimiter, "--perm", "-perm")
+		params.journal = args.ParsePairs(delimiter, "--journal", "-journal")
+		params.overwrite = args.ParsePairs(delimiter, "--overwrite", "-overwrite")
+		params.destTemplate = args.ParsePairs(delimiter, "--dest-template", "-dest-template")
+		params.to = args.ParsePairs(delimiter, "--to", "-to")
+		params.find = args.ParsePairs(delimiter, "--find", "-find")
+		params.with = args.ParsePairs(delimiter, "--with", "-with")
+		params.afterContext = args.ParsePairs(delimiter, "-A", "--after-context", "-after-context")
+		params.beforeContext = args.ParsePairs(delimiter, "-B", "--before-context", "-before-context")
+		params.context = args.ParsePairs(delimiter, "-C", "--context", "-context")
+		params.format = args.ParsePairs(delimiter, "--format", "-format")
+		params.filesFrom = args.ParsePairs(delimiter, "--files-from", "-files-from")
+		// value is optional, i.e. no blank between flag and value
+		delimiterOpt := osargs.NewDelimiter(false, false, "=")
+		params.preserve = args.ParsePairs(delimiterOpt, "-p", "--preserve", "-preserve")
+		params.verify = args.ParsePairs(delimiterOpt, "--verify", "-verify")
+		params.backup = args.ParsePairs(delimiterOpt, "--backup", "-backup")
+		params.color = args.ParsePairs(delimiterOpt, "--color", "-color")
 		params.help = args.Parse("-h", "--help", "-help", "help")
 		params.version = args.Parse("-v", "--version", "-version", "version")
 		params.example = args.Parse("-e", "--example", "-example", "example")
 		params.copyright = args.Parse("-c", "--copyright", "-copyright", "copyright")
 		params.or = args.Parse("-o", "--or", "-or", "or")
+		params.regex = args.Parse("-x", "--regex", "-regex")
+		params.boolean = args.Parse("-b", "--boolean", "-boolean")
+		params.ignoreCase = args.Parse("-i", "--ignore-case", "-ignore-case")
+		params.decompress = args.Parse("-z", "--decompress", "-decompress")
+		params.archives = args.Parse("-a", "--archives", "-archives")
+		params.ignoreFiles = args.Parse("-g", "--ignore-files", "-ignore-files")
+		params.executable = args.Parse("--executable", "-executable")
+		params.dryRun = args.Parse("-n", "--dry-run", "-dry-run")
+		params.trash = args.Parse("--trash", "-trash")
+		params.noTrash = args.Parse("--no-trash", "-no-trash")
+		params.rename = args.Parse("--rename", "-rename")
+		params.manifest = args.Parse("--manifest", "-manifest")
+		params.flatten = args.Parse("--flatten", "-flatten")
+		params.diff = args.Parse("--diff", "-diff")
+		params.lines = args.Parse("--lines", "-lines")
+		params.print0 = args.Parse("-0", "--print0", "-print0")
 		params.silent = args.Parse("-s", "--silent", "-silent", "silent")
 		params.threads = args.Parse("-t", "--threads", "-threads", "threads")
-		params.command = args.Parse(argCOUNT, argCP, argMV, argPRINT, argRM)
+		params.command = args.Parse(argCOUNT, argCP, argMV, argPRINT, argRENAME, argREPLACE, argREPORT, argRM, argUNDO)
 		params.recursive = args.Parse("-r", "--recursive", "-recursive", "recursive")
 		params.input = new(osargs.Result)
 		params.output = new(osargs.Result)
@@ -194,29 +355,152 @@ func (params *tParameters) parseContentFilter(unparsedArgs []string) {
 func (params *tParameters) parseFileNameFilter() {
 	if params.input.Available() {
 		input := params.input.Values[0]
-		separator := pathSeparator(input)
-		fileNameBegin := rindex(input, separator) + 1
-		fileName := input[fileNameBegin:]
-		if rindex(fileName, '*') >= 0 {
+		// file name filter begins with the first path element containing wildcards
+		fileNameBegin := globBegin(input)
+		if fileNameBegin >= 0 {
 			// directory; remove ending separator, eventually
-			input = filepath.Join(input[:fileNameBegin], ".")
-			params.fileNameFilter = fileName
-			params.input.Values[0] = input
+			params.fileNameFilter = filepath.ToSlash(input[fileNameBegin:])
+			params.input.Values[0] = filepath.Join(input[:fileNameBegin], ".")
 		} else {
 			params.fileNameFilter = "*"
 		}
 	}
 }
 
+// globBegin returns the beginning of the first element of path, that contains
+// wildcards and isn't an existing directory, or -1. Directories like /tmp/g[1]
+// (e.g. the current directory) are no patterns. path is absolute, i.e. has only
+// OS specific separators.
+func globBegin(path string) int {
+	for begin := 0; begin < len(path); {
+		end := strings.IndexByte(path[begin:], filepath.Separator)
+		if end < 0 {
+			end = len(path)
+		} else {
+			end += begin
+		}
+		if strings.ContainsAny(path[begin:end], "*?[{") {
+			info, err := os.Stat(path[:end])
+			if err != nil || !info.IsDir() {
+				return begin
+			}
+		}
+		begin = end + 1
+	}
+	return -1
+}
+
 func (params *tParameters) validateParameters() error {
 	var err error
 	paramsInfo := params.infoParameters()
 	paramsCmd := params.commandParameters()
-	if parametersIncompatible(paramsInfo, paramsCmd) || params.isMultiple() {
+	if parametersIncompatible(paramsInfo, paramsCmd) || params.isMultiple() || params.or.Available() && params.boolean.Available() || params.trash.Available() && params.noTrash.Available() || params.overwrite.Available() && params.rename.Available() || params.flatten.Available() && params.destTemplate.Available() || params.backup.Available() && params.diff.Available() {
 		err = errors.New("wrong argument usage")
 	} else if anyAvailable(paramsCmd) {
-		if params.command.Available() {
+		if params.command.Available() && params.command.Values[0] == argUNDO {
+			err = params.validateJournalFile()
+		} else if params.command.Available() {
 			err = params.validateIODirectories()
+			if err == nil && params.archives.Available() && !params.archivesSupported() {
+				err = errors.New("archives option is not supported by " + params.command.Values[0])
+			}
+			if err == nil && params.journal.Available() && !params.journalSupported() {
+				err = errors.New("journal option is not supported by " + params.command.Values[0])
+			}
+			if err == nil && (params.trash.Available() || params.noTrash.Available()) && params.command.Values[0] != argRM {
+				err = errors.New("trash option is not supported by " + params.command.Values[0])
+			}
+			if err == nil && (params.overwrite.Available() || params.rename.Available()) && !params.outputDirNeeded() {
+				err = errors.New("overwrite and rename options are not supported by " + params.command.Values[0])
+			}
+			if err == nil && params.overwrite.Available() {
+				_, err = parseOverwritePolicy(params.overwrite.Values[0])
+			}
+			if err == nil && params.preserve.Available() {
+				if params.outputDirNeeded() || params.command.Values[0] == argREPLACE {
+					_, err = parsePreserve(params.preserve.Values[0])
+				} else {
+					err = errors.New("preserve option is not supported by " + params.command.Values[0])
+				}
+			}
+			if err == nil && (params.verify.Available() || params.manifest.Available()) && !params.outputDirNeeded() {
+				err = errors.New("verify option is not supported by " + params.command.Values[0])
+			}
+			if err == nil && params.verify.Available() {
+				_, err = parseHashAlgorithm(params.verify.Values[0])
+			}
+			if err == nil && (params.flatten.Available() || params.destTemplate.Available()) && !params.outputDirNeeded() {
+				err = errors.New("flatten and dest-template options are not supported by " + params.command.Values[0])
+			}
+			if err == nil && params.destTemplate.Available() {
+				_, err = newDestTemplate(params.destTemplate.Values[0])
+			}
+			if err == nil && params.to.Available() && params.command.Values[0] != argRENAME {
+				err = errors.New("to option is not supported by " + params.command.Values[0])
+			} else if err == nil && !params.to.Available() && params.command.Values[0] == argRENAME {
+				err = errors.New("rename template is not specified")
+			}
+			if err == nil && params.command.Values[0] == argREPLACE {
+				if !params.find.Available() || !params.with.Available() {
+					err = errors.New("find or with option is not specified")
+				} else if params.decompress.Available() {
+					err = errors.New("decompress option is not supported by " + params.command.Values[0])
+				} else if params.encoding.Available() && isConverted(params.encoding.Values[0]) {
+					// replacement works on raw content
+					err = errors.New("encoding option is not supported by " + params.command.Values[0] + " (only auto and utf-8)")
+				} else {
+					_, err = newReplacer(params)
+				}
+			} else if err == nil && (params.find.Available() || params.with.Available() || params.backup.Available() || params.diff.Available()) {
+				err = errors.New("find, with, backup and diff options are not supported by " + params.command.Values[0])
+			}
+			if err == nil && params.command.Values[0] == argREPORT && len(params.contentFilter) == 0 {
+				err = errors.New("report command needs filter")
+			}
+			if err == nil && params.isLines() && params.command.Values[0] != argPRINT {
+				err = errors.New("lines, context and color options are not supported by " + params.command.Values[0])
+			} else if err == nil && params.isLines() && len(params.contentFilter) == 0 {
+				err = errors.New("lines option needs filter")
+			} else if err == nil && params.isLines() {
+				_, _, err = params.contextLines()
+				if err == nil && params.color.Available() {
+					_, err = parseColor(params.color.Values[0])
+				}
+			}
+			if err == nil && params.format.Available() {
+				var format int
+				format, err = parseFormat(params.format.Values[0])
+				if err == nil && format != formatTEXT && (params.isLines() || params.diff.Available()) {
+					err = errors.New("lines, context, color and diff options are not supported by format " + params.format.Values[0])
+				}
+			}
+			if err == nil && params.print0.Available() && params.command.Values[0] != argPRINT {
+				err = errors.New("print0 option is not supported by " + params.command.Values[0])
+			} else if err == nil && params.print0.Available() && (params.isLines() || params.format.Available() && params.format.Values[0] != "text") {
+				err = errors.New("print0 option is not supported with lines, context, color and format options")
+			}
+			if err == nil && params.filesFrom.Available() && (params.recursive.Available() || params.ignoreFiles.Available()) {
+				err = errors.New("recursive and ignore-files options are not supported with files-from")
+			}
+			if err == nil {
+				params.nameFilter, err = newNameFilter(params)
+				if err == nil {
+					params.metaFilter, err = newMetaFilter(params)
+					if err == nil {
+						params.filter, err = newContentFilter(params)
+						if err == nil && params.to.Available() {
+							_, err = newRenameTemplate(params.to.Values[0], params.nameFilter.fileName.wildcards(), params.filter.groupCount())
+						}
+						// in dry run nothing is logged
+						if err == nil && params.journal.Available() && !params.dryRun.Available() {
+							params.journalLog, err = openJournal(params.journal.Values[0])
+						}
+						if err == nil && params.isTrash() && !params.dryRun.Available() {
+							params.trashBin, err = newTrash()
+						}
+					}
+				}
+			}
 		} else {
 			err = errors.New("command missing")
 		}
@@ -234,18 +518,58 @@ func (params *tParameters) infoParameters() []*osargs.Result {
 }
 
 func (params *tParameters) commandParameters() []*osargs.Result {
-	paramsCmd := make([]*osargs.Result, 6)
+	paramsCmd := make([]*osargs.Result, 46)
 	paramsCmd[0] = params.command
 	paramsCmd[1] = params.input
 	paramsCmd[2] = params.or
 	paramsCmd[3] = params.output
 	paramsCmd[4] = params.recursive
 	paramsCmd[5] = params.threads
+	paramsCmd[6] = params.regex
+	paramsCmd[7] = params.boolean
+	paramsCmd[8] = params.ignoreCase
+	paramsCmd[9] = params.encoding
+	paramsCmd[10] = params.decompress
+	paramsCmd[11] = params.archives
+	paramsCmd[12] = params.include
+	paramsCmd[13] = params.exclude
+	paramsCmd[14] = params.includeDir
+	paramsCmd[15] = params.excludeDir
+	paramsCmd[16] = params.ignoreFiles
+	paramsCmd[17] = params.size
+	paramsCmd[18] = params.newer
+	paramsCmd[19] = params.older
+	paramsCmd[20] = params.perm
+	paramsCmd[21] = params.executable
+	paramsCmd[22] = params.dryRun
+	paramsCmd[23] = params.journal
+	paramsCmd[24] = params.trash
+	paramsCmd[25] = params.noTrash
+	paramsCmd[26] = params.overwrite
+	paramsCmd[27] = params.rename
+	paramsCmd[28] = params.preserve
+	paramsCmd[29] = params.verify
+	paramsCmd[30] = params.manifest
+	paramsCmd[31] = params.flatten
+	paramsCmd[32] = params.destTemplate
+	paramsCmd[33] = params.to
+	paramsCmd[34] = params.find
+	paramsCmd[35] = params.with
+	paramsCmd[36] = params.backup
+	paramsCmd[37] = params.diff
+	paramsCmd[38] = params.lines
+	paramsCmd[39] = params.afterContext
+	paramsCmd[40] = params.beforeContext
+	paramsCmd[41] = params.context
+	paramsCmd[42] = params.color
+	paramsCmd[43] = params.format
+	paramsCmd[44] = params.print0
+	paramsCmd[45] = params.filesFrom
 	return paramsCmd
 }
 
 func (params *tParameters) isMultiple() bool {
-	paramsMult := make([]*osargs.Result, 10)
+	paramsMult := make([]*osargs.Result, 45)
 	paramsMult[0] = params.command
 	paramsMult[1] = params.copyright
 	paramsMult[2] = params.example
@@ -256,6 +580,41 @@ func (params *tParameters) isMultiple() bool {
 	paramsMult[7] = params.output
 	paramsMult[8] = params.recursive
 	paramsMult[9] = params.version
+	paramsMult[10] = params.regex
+	paramsMult[11] = params.boolean
+	paramsMult[12] = params.ignoreCase
+	paramsMult[13] = params.encoding
+	paramsMult[14] = params.decompress
+	paramsMult[15] = params.archives
+	paramsMult[16] = params.ignoreFiles
+	paramsMult[17] = params.newer
+	paramsMult[18] = params.older
+	paramsMult[19] = params.perm
+	paramsMult[20] = params.executable
+	paramsMult[21] = params.dryRun
+	paramsMult[22] = params.journal
+	paramsMult[23] = params.trash
+	paramsMult[24] = params.noTrash
+	paramsMult[25] = params.overwrite
+	paramsMult[26] = params.rename
+	paramsMult[27] = params.preserve
+	paramsMult[28] = params.verify
+	paramsMult[29] = params.manifest
+	paramsMult[30] = params.flatten
+	paramsMult[31] = params.destTemplate
+	paramsMult[32] = params.to
+	paramsMult[33] = params.find
+	paramsMult[34] = params.with
+	paramsMult[35] = params.backup
+	paramsMult[36] = params.diff
+	paramsMult[37] = params.lines
+	paramsMult[38] = params.afterContext
+	paramsMult[39] = params.beforeContext
+	paramsMult[40] = params.context
+	paramsMult[41] = params.color
+	paramsMult[42] = params.format
+	paramsMult[43] = params.print0
+	paramsMult[44] = params.filesFrom
 	for _, param := range paramsMult {
 		if param.Count() > 1 {
 			return true
@@ -284,6 +643,33 @@ func (params *tParameters) validateIODirectories() error {
 	return err
 }
 
+// validateJournalFile checks input of undo command. Filters and options
+// other than dry run, format and silent are not allowed.
+func (params *tParameters) validateJournalFile() error {
+	if !params.input.Available() {
+		return errors.New("journal is not specified")
+	} else if len(params.contentFilter) > 0 || params.fileNameFilter != "*" {
+		return errors.New("wrong argument usage")
+	}
+	for _, param := range params.commandParameters()[2:] {
+		if param.Available() && param != params.dryRun && param != params.format {
+			return errors.New("wrong argument usage")
+		}
+	}
+	if params.format.Available() {
+		if _, err := parseFormat(params.format.Values[0]); err != nil {
+			return err
+		}
+	}
+	info, err := os.Stat(params.input.Values[0])
+	if err == nil && info.IsDir() {
+		err = errors.New("journal path is a directory, but must be a file")
+	} else if err != nil && os.IsNotExist(err) {
+		err = errors.New("journal does not exist")
+	}
+	return err
+}
+
 func (params *tParameters) outputDirNeeded() bool {
 	if params.command.Available() {
 		command := params.command.Values[0]
@@ -294,6 +680,51 @@ func (params *tParameters) outputDirNeeded() bool {
 	return false
 }
 
+// archivesSupported returns false for commands, that can't be applied to archive members.
+func (params *tParameters) archivesSupported() bool {
+	command := params.command.Values[0]
+	return command != argMV && command != argRENAME && command != argREPLACE && command != argRM
+}
+
+// isTrash returns true, if rm moves files to trash. Trash is default, if
+// environment variable FBC_TRASH is set.
+func (params *tParameters) isTrash() bool {
+	if params.command.Values[0] == argRM {
+		return params.trash.Available() || !params.noTrash.Available() && isTrashDefault()
+	}
+	return false
+}
+
+// isLines returns true, if print outputs matching lines. Context and color
+// options imply it.
+func (params *tParameters) isLines() bool {
+	return params.lines.Available() || params.afterContext.Available() || params.beforeContext.Available() || params.context.Available() || params.color.Available()
+}
+
+// contextLines returns number of context lines before and after matching
+// lines. -A and -B override -C.
+func (params *tParameters) contextLines() (int, int, error) {
+	var before, after int
+	var err error
+	if params.context.Available() {
+		before, err = parseContext(params.context.Values[0])
+		after = before
+	}
+	if err == nil && params.beforeContext.Available() {
+		before, err = parseContext(params.beforeContext.Values[0])
+	}
+	if err == nil && params.afterContext.Available() {
+		after, err = parseContext(params.afterContext.Values[0])
+	}
+	return before, after, err
+}
+
+// journalSupported returns true for commands, that can be undone.
+func (params *tParameters) journalSupported() bool {
+	command := params.command.Values[0]
+	return command == argMV || command == argRENAME || command == argRM
+}
+
 func parametersIncompatible(paramsInfo, paramsCmd []*osargs.Result) bool {
 	// either info or command
 	if anyAvailable(paramsInfo) && anyAvailable(paramsCmd) {
@@ -355,7 +786,27 @@ func newFileProcessor(params *tParameters) tFileProcessor {
 	case argPRINT:
 		processorPrint := new(tFileProcessorPrint)
 		processorPrint.init(params)
+		if params.isLines() {
+			var color bool
+			before, after, _ := params.contextLines()
+			if params.color.Available() {
+				color, _ = parseColor(params.color.Values[0])
+			}
+			processorPrint.linePrinter = newLinePrinter(params.filter, before, after, color)
+		}
 		return processorPrint
+	case argRENAME:
+		processorRename := new(tFileProcessorRename)
+		processorRename.init(params)
+		return processorRename
+	case argREPLACE:
+		processorReplace := new(tFileProcessorReplace)
+		processorReplace.init(params)
+		return processorReplace
+	case argREPORT:
+		processorReport := new(tFileProcessorReport)
+		processorReport.init(params)
+		return processorReport
 	case argRM:
 		processorRM := new(tFileProcessorRM)
 		processorRM.init(params)
@@ -369,19 +820,28 @@ func newFileProcessor(params *tParameters) tFileProcessor {
 func (proc *tFileProcessorDefault) init(params *tParameters) {
 	proc.inputDirLength = dirLengthWOEndingSeparator(params.input.Values[0]) + 1
 	proc.silent = params.silent.Available()
-	proc.or = params.or.Available()
 	proc.threads = params.threads.Available()
-	proc.contentFilter = toBytes(params.contentFilter)
-	proc.fileNameFilter = strings.Split(params.fileNameFilter, "*")
+	proc.archives = params.archives.Available()
+	proc.dryRun = params.dryRun.Available()
+	proc.contentFilter = params.filter
+	proc.nameFilter = params.nameFilter
+	proc.metaFilter = params.metaFilter
+	proc.journal = params.journalLog
 	if !proc.threads {
-		proc.buffer = make([]byte, 1024*1024*4)
+		proc.buffer = make([]byte, bufferSize)
 	}
 }
 
 func (proc *tFileProcessorDefault) ProcessFile(path string, info os.FileInfo, err error) error {
 	var match bool
-	if err == nil && proc.isFileNameMatch(info.Name()) {
-		match, err = proc.isContentMatch(path)
+	if err == nil && proc.isArchive(info.Name()) {
+		return proc.processArchive(path, nil)
+	} else if err == nil && proc.isFileMatch(path, info) {
+		var found []bool
+		match, found, err = proc.isContentMatch(path)
+		if err == nil && match {
+			proc.printFile(path, info, found, "match", "")
+		}
 	}
 	return proc.postProcess(match, err)
 }
@@ -405,6 +865,7 @@ func (proc *tFileProcessorDefault) postProcess(match bool, err error) error {
 }
 
 func (proc *tFileProcessorDefault) printSummary(err error) {
+	err = proc.closeJournal(err)
 	if err == nil {
 		printFinished(proc.count)
 	} else {
@@ -412,42 +873,69 @@ func (proc *tFileProcessorDefault) printSummary(err error) {
 	}
 }
 
-func (proc *tFileProcessorDefault) isFileNameMatch(name string) bool {
-	if len(proc.fileNameFilter) > 0 {
-		if strings.HasPrefix(name, proc.fileNameFilter[0]) {
-			offset := len(proc.fileNameFilter[0])
-			for _, part := range proc.fileNameFilter[1:] {
-				if len(part) > 0 {
-					offsetPrev, limit := offset, len(name)-len(part)+1
-					for i := offset; i < limit; i++ {
-						if strings.HasPrefix(name[i:], part) {
-							offset = i + len(part)
-							break
-						}
-					}
-					if offset == offsetPrev {
-						return false
-					}
-				} else {
-					// last part can be empty; this matches rest of string
-					return true
-				}
-			}
-			return offset == len(name)
+// closeJournal closes journal, if available, and returns err or error on close.
+func (proc *tFileProcessorDefault) closeJournal(err error) error {
+	if proc.journal != nil {
+		errClose := proc.journal.close()
+		if err == nil {
+			err = errClose
 		}
-		return false
 	}
-	return true
+	return err
 }
 
-func (proc *tFileProcessorDefault) isContentMatch(path string) (bool, error) {
-	if len(proc.contentFilter) > 0 {
-		if proc.or {
-			return check.FileContainsAny(path, proc.buffer, proc.contentFilter)
+// isFileMatch returns true, if file passes name and metadata filters.
+func (proc *tFileProcessorDefault) isFileMatch(path string, info os.FileInfo) bool {
+	return proc.nameFilter.isFileMatch(path[proc.inputDirLength:], info.Name()) && proc.metaFilter.isMatch(info)
+}
+
+func (proc *tFileProcessorDefault) isDirMatch(path string, info os.FileInfo) bool {
+	return proc.nameFilter.isDirMatch(path[proc.inputDirLength:], info.Name())
+}
+
+// isArchive returns true, if file is an archive, that must be processed like a directory.
+func (proc *tFileProcessorDefault) isArchive(name string) bool {
+	return proc.archives && isArchive(name)
+}
+
+// isContentMatch returns true, if content of file at path passes content
+// filter. Returns found filter terms, too.
+func (proc *tFileProcessorDefault) isContentMatch(path string) (bool, []bool, error) {
+	if len(proc.contentFilter.terms) > 0 {
+		file, err := os.Open(path)
+		if err == nil {
+			defer file.Close()
+			return proc.isReaderMatch(file)
 		}
-		return check.FileContainsAll(path, proc.buffer, proc.contentFilter)
+		return false, nil, err
+	}
+	return true, nil, nil
+}
+
+func (proc *tFileProcessorDefault) isReaderMatch(reader io.Reader) (bool, []bool, error) {
+	if len(proc.contentFilter.terms) > 0 {
+		contentReader, err := proc.contentFilter.contentReader(reader)
+		if err == nil {
+			defer contentReader.Close()
+			if proc.threads {
+				buffer := bufferPool.Get().(*[]byte)
+				defer bufferPool.Put(buffer)
+				return proc.contentFilter.findTerms(contentReader, *buffer)
+			}
+			return proc.contentFilter.findTerms(contentReader, proc.buffer)
+		}
+		return false, nil, err
+	}
+	return true, nil, nil
+}
+
+// printFile writes record of file at path, if records are enabled. found are
+// the found filter terms, action the performed operation and target the
+// resulting file.
+func (proc *tFileProcessorDefault) printFile(path string, info os.FileInfo, found []bool, action, target string) {
+	if records.enabled() {
+		records.write(newFileRecord(path, path[proc.inputDirLength:], info, proc.contentFilter.names, found, action, target))
 	}
-	return true, nil
 }
 
 func (proc *tFileProcessorCount) printSummary(err error) {
@@ -462,37 +950,298 @@ func (proc *tFileProcessorCP) init(params *tParameters) {
 	proc.tFileProcessorDefault.init(params)
 	proc.existingDirs = make([]string, 0, 16)
 	proc.outputDir = params.output.Values[0]
+	proc.plannedFiles = make(map[string]bool)
+	proc.conflictWarning = !params.overwrite.Available() && !params.rename.Available()
+	proc.rename = params.rename.Available()
+	proc.verb = "copied"
+	if params.overwrite.Available() {
+		proc.overwrite, _ = parseOverwritePolicy(params.overwrite.Values[0])
+	}
+	if params.preserve.Available() {
+		proc.preserve, _ = parsePreserve(params.preserve.Values[0])
+	}
+	proc.inputDir = params.inputDir()
+	if params.flatten.Available() {
+		proc.template, _ = newDestTemplate("{name}")
+	} else if params.destTemplate.Available() {
+		proc.template, _ = newDestTemplate(params.destTemplate.Values[0])
+	}
+	// manifest implies verification
+	if params.verify.Available() || params.manifest.Available() {
+		var algorithm int
+		if params.verify.Available() {
+			algorithm, _ = parseHashAlgorithm(params.verify.Values[0])
+		}
+		proc.verifier = newVerifier(algorithm, proc.outputDir, params.manifest.Available())
+	}
+}
+
+func (proc *tFileProcessorCP) printSummary(err error) {
+	// with template output directories don't correspond to input directories
+	if proc.template == nil {
+		proc.applyDirMetadata()
+	}
+	err = proc.closeJournal(err)
+	if proc.verifier != nil && !proc.dryRun {
+		if err == nil && proc.verifier.manifest {
+			err = proc.verifier.writeManifest()
+		}
+		if err == nil && proc.verifier.mismatches > 0 {
+			err = errors.New("checksum mismatch in " + filesStr(proc.verifier.mismatches))
+		}
+	}
+	if err == nil {
+		printFinishedSummary(proc.count, proc.stats.summary(proc.verb))
+	} else {
+		printError(err)
+	}
 }
 
 func (proc *tFileProcessorCP) ProcessFile(path string, info os.FileInfo, err error) error {
 	var match bool
-	if err == nil && proc.isFileNameMatch(info.Name()) {
-		match, err = proc.isContentMatch(path)
+	if err == nil && proc.isArchive(info.Name()) {
+		return proc.processArchive(path, func(member *tArchiveMember, found []bool) error {
+			return proc.copyMember(path, member, found)
+		})
+	} else if err == nil && proc.isFileMatch(path, info) {
+		var found []bool
+		match, found, err = proc.isContentMatch(path)
 		if err == nil && match {
-			subDir := path[proc.inputDirLength : len(path)-len(info.Name())]
-			outputPath := filepath.Join(proc.outputDir, subDir)
-			err = proc.ensureDir(outputPath, subDir)
+			var inputFile *os.File
+			inputFile, err = os.Open(path)
 			if err == nil {
-				outputPath = filepath.Join(outputPath, info.Name())
-				if !check.FileExists(outputPath) {
-					var inputFile *os.File
-					inputFile, err = os.Open(path)
+				defer inputFile.Close()
+				subDir := path[proc.inputDirLength : len(path)-len(info.Name())]
+				err = proc.copyContent(path, inputFile, info, subDir, found)
+			}
+		}
+	}
+	return proc.postProcess(match, err)
+}
+
+// copyMember extracts member of archive. Archive is treated like a directory.
+func (proc *tFileProcessorCP) copyMember(archivePath string, member *tArchiveMember, found []bool) error {
+	subDir := filepath.Join(archivePath[proc.inputDirLength:], filepath.FromSlash(path.Dir(member.name)))
+	return proc.copyContent(archivePath+archiveSeparator+member.name, member.reader, member.info, subDir, found)
+}
+
+// copyContent writes content of file info to output directory. subDir is
+// the directory of file relative to input directory. source is for output
+// and extended attributes only. found are the found filter terms.
+func (proc *tFileProcessorCP) copyContent(source string, reader io.Reader, info os.FileInfo, subDir string, found []bool) error {
+	outputSubDir, name, err := proc.targetPath(info, subDir)
+	if err == nil {
+		outputPath := filepath.Join(proc.outputDir, outputSubDir)
+		err = proc.ensureDir(outputPath, outputSubDir)
+		if err == nil {
+			var action int
+			outputPath, action, err = proc.resolveTarget(filepath.Join(outputPath, name), info, outputSubDir)
+			if err == nil && action == actionCOMPARE {
+				action, err = proc.copyIfDifferent(source, reader, info, outputPath)
+			} else if err == nil && action != actionSKIP {
+				if proc.dryRun && action == actionOVERWRITE {
+					printOperation("overwrite", source, outputPath)
+				} else if proc.dryRun {
+					printOperation("copy", source, outputPath)
+				} else {
+					var tempPath string
+					var digest hash.Hash
+					reader, digest = proc.teeHash(reader)
+					tempPath, err = writeTemp(filepath.Dir(outputPath), reader)
 					if err == nil {
-						var outputFile *os.File
-						defer inputFile.Close()
-						outputFile, err = os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+						err = proc.commitCopy(tempPath, outputPath, source, info)
 						if err == nil {
-							defer outputFile.Close()
-							_, err = io.Copy(outputFile, inputFile)
+							err = proc.verifyCopy(outputPath, digest)
 						}
 					}
-				} else {
-					err = errors.New("target file already exists: " + filepath.Join(subDir, info.Name()))
 				}
 			}
+			if err == nil {
+				proc.countAction(action)
+				proc.printFile(source, info, found, actionName(action, false), outputPath)
+			}
 		}
 	}
-	return proc.postProcess(match, err)
+	return err
+}
+
+// copyIfDifferent overwrites file at path, if content of reader differs.
+// Content is written to a temporary file first, since reader can be read only once.
+func (proc *tFileProcessorCP) copyIfDifferent(source string, reader io.Reader, info os.FileInfo, path string) (int, error) {
+	var equal bool
+	var err error
+	if proc.dryRun {
+		equal, err = isReaderEqual(reader, path)
+		if err == nil && !equal {
+			printOperation("overwrite", source, path)
+		}
+	} else {
+		var targetFile *os.File
+		targetFile, err = os.Open(path)
+		if err == nil {
+			var tempPath string
+			var digest hash.Hash
+			compareWriter := newCompareWriter(targetFile)
+			reader, digest = proc.teeHash(reader)
+			tempPath, err = writeTemp(filepath.Dir(path), io.TeeReader(reader, compareWriter))
+			equal = compareWriter.isEqual()
+			targetFile.Close()
+			if err == nil && equal {
+				os.Remove(tempPath)
+			} else if err == nil {
+				err = proc.commitCopy(tempPath, path, source, info)
+				if err == nil {
+					err = proc.verifyCopy(path, digest)
+				}
+			}
+		}
+	}
+	if equal {
+		return actionSKIP, err
+	}
+	return actionOVERWRITE, err
+}
+
+// commitCopy applies metadata to temporary file and renames it to path.
+func (proc *tFileProcessorCP) commitCopy(tempPath, path, source string, info os.FileInfo) error {
+	var err error
+	if proc.preserve != 0 {
+		err = applyMetadata(tempPath, source, info, proc.preserve)
+	}
+	if err == nil {
+		return commitTemp(tempPath, path)
+	}
+	os.Remove(tempPath)
+	return err
+}
+
+// teeHash returns reader, that computes checksum of read content, if
+// verification is enabled. Otherwise digest is nil.
+func (proc *tFileProcessorCP) teeHash(reader io.Reader) (io.Reader, hash.Hash) {
+	if proc.verifier != nil {
+		digest := proc.verifier.newHash()
+		return io.TeeReader(reader, digest), digest
+	}
+	return reader, nil
+}
+
+// verifyCopy compares checksum of file at path with digest of source, if digest is not nil.
+func (proc *tFileProcessorCP) verifyCopy(path string, digest hash.Hash) error {
+	if digest != nil {
+		return proc.verifier.verify(path, hex.EncodeToString(digest.Sum(nil)))
+	}
+	return nil
+}
+
+// targetPath returns subdirectory in output directory and file name of target.
+// subDir is the directory of file info relative to input directory.
+func (proc *tFileProcessorCP) targetPath(info os.FileInfo, subDir string) (string, string, error) {
+	if proc.template != nil {
+		relPath, err := relativePath(proc.template.expand(fileFields(info, subDir)))
+		outputSubDir, name := filepath.Split(relPath)
+		return outputSubDir, name, err
+	}
+	return subDir, info.Name(), nil
+}
+
+// resolveTarget returns path and action for target file at path according to
+// overwrite policy. info is the source file. Targets of several files (with
+// template) get numbered names, that follow overwrite policy, too.
+func (proc *tFileProcessorCP) resolveTarget(path string, info os.FileInfo, subDir string) (string, int, error) {
+	var numbered bool
+	state := proc.targetState(path)
+	if state == targetPLANNED && proc.template != nil && !proc.rename {
+		path, state = proc.numberedTarget(path)
+		numbered = true
+	}
+	if state == targetFREE && numbered {
+		return path, actionRENAME, nil
+	} else if state == targetFREE {
+		return path, actionCOPY, nil
+	} else if proc.rename || state == targetPLANNED && proc.template != nil {
+		return proc.uniquePath(path), actionRENAME, nil
+	}
+	targetInfo, err := os.Stat(path)
+	if err == nil && !targetInfo.IsDir() {
+		action := overwriteAction(proc.overwrite, info, targetInfo)
+		if action == actionSKIP && proc.conflictWarning && !proc.silent {
+			printWarning(errors.New("target file already exists: " + filepath.Join(subDir, filepath.Base(path))))
+		}
+		return path, action, nil
+	} else if err == nil {
+		err = errors.New("target is a directory: " + filepath.Join(subDir, filepath.Base(path)))
+	} else if os.IsNotExist(err) {
+		// planned in dry run
+		err = errors.New("target file already exists: " + filepath.Join(subDir, filepath.Base(path)))
+	}
+	return path, actionSKIP, err
+}
+
+// targetState returns targetFREE, if file doesn't exist. In dry run, or if
+// targets are built from template, the file is reserved, since it would exist
+// after the operation. Existing files are reserved, too, so that they are
+// overwritten only once.
+func (proc *tFileProcessorCP) targetState(path string) int {
+	if proc.dryRun || proc.template != nil {
+		if proc.threads {
+			proc.mutex.Lock()
+			defer proc.mutex.Unlock()
+		}
+		if proc.plannedFiles[path] {
+			return targetPLANNED
+		}
+		proc.plannedFiles[path] = true
+		if check.FileExists(path) {
+			return targetEXISTS
+		}
+		return targetFREE
+	} else if check.FileExists(path) {
+		return targetEXISTS
+	}
+	return targetFREE
+}
+
+// numberedTarget returns path with lowest number, that is not planned, e.g.
+// "name (1).txt", and its state. Existing files are not skipped, so that
+// repeated runs get the same targets.
+func (proc *tFileProcessorCP) numberedTarget(path string) (string, int) {
+	if proc.threads {
+		proc.mutex.Lock()
+		defer proc.mutex.Unlock()
+	}
+	for i := 1; ; i++ {
+		numberedPath := numberedPath(path, i)
+		if !proc.plannedFiles[numberedPath] {
+			proc.plannedFiles[numberedPath] = true
+			if check.FileExists(numberedPath) {
+				return numberedPath, targetEXISTS
+			}
+			return numberedPath, targetFREE
+		}
+	}
+}
+
+// uniquePath returns path with lowest number, that is not used, e.g. "name (1).txt".
+func (proc *tFileProcessorCP) uniquePath(path string) string {
+	if proc.threads {
+		proc.mutex.Lock()
+		defer proc.mutex.Unlock()
+	}
+	for i := 1; ; i++ {
+		uniquePath := numberedPath(path, i)
+		if !proc.plannedFiles[uniquePath] && !check.FileExists(uniquePath) {
+			proc.plannedFiles[uniquePath] = true
+			return uniquePath
+		}
+	}
+}
+
+func (proc *tFileProcessorCP) countAction(action int) {
+	if proc.threads {
+		proc.mutex.Lock()
+		defer proc.mutex.Unlock()
+	}
+	proc.stats.add(action)
 }
 
 func (proc *tFileProcessorCP) ensureDir(dir, subDir string) error {
@@ -520,14 +1269,28 @@ func (proc *tFileProcessorCP) ensureDirThr(dir, subDir string) error {
 
 func (proc *tFileProcessorCP) ensureDirSeq(dir, subDir string) error {
 	info, err := os.Stat(dir)
-	if err != nil && os.IsNotExist(err) {
+	if err != nil && os.IsNotExist(err) && proc.dryRun {
+		if !proc.isPlannedParentDir(dir) {
+			printOperation("mkdir", dir)
+		}
+		proc.existingDirs = append(proc.existingDirs, dir)
+		err = nil
+	} else if err != nil && os.IsNotExist(err) {
+		var missingDirs []string
+		if proc.preserve != 0 {
+			missingDirs = proc.missingDirs(dir)
+		}
 		err = os.MkdirAll(dir, 0777)
 		if err == nil || check.FileExists(dir) {
 			proc.existingDirs = append(proc.existingDirs, dir)
+			proc.createdDirs = append(proc.createdDirs, missingDirs...)
 			err = nil
 		}
 	} else if info != nil && err == nil {
 		if info.IsDir() {
+			if !proc.dryRun {
+				removeTempFiles(dir)
+			}
 			proc.existingDirs = append(proc.existingDirs, dir)
 		} else {
 			err = errors.New("can't create directory (already exists as file): " + filepath.Join(subDir, info.Name()))
@@ -536,20 +1299,99 @@ func (proc *tFileProcessorCP) ensureDirSeq(dir, subDir string) error {
 	return err
 }
 
+// missingDirs returns dir and its parents in output directory, that don't exist.
+func (proc *tFileProcessorCP) missingDirs(dir string) []string {
+	var dirs []string
+	for len(dir) > len(proc.outputDir) && !check.FileExists(dir) {
+		dirs = append(dirs, dir)
+		dir = filepath.Dir(dir)
+	}
+	return dirs
+}
+
+// applyDirMetadata sets metadata of input directories to created output
+// directories. Subdirectories are processed first, since their parents may
+// become read-only.
+func (proc *tFileProcessorCP) applyDirMetadata() {
+	sort.Slice(proc.createdDirs, func(i, j int) bool {
+		return len(proc.createdDirs[i]) > len(proc.createdDirs[j])
+	})
+	for _, dir := range proc.createdDirs {
+		source := filepath.Join(proc.inputDir, dir[dirLengthWOEndingSeparator(proc.outputDir)+1:])
+		info, err := os.Stat(source)
+		// directories of archive members have no input directory
+		if err == nil && info.IsDir() {
+			err = applyMetadata(dir, source, info, proc.preserve)
+			if err != nil && !proc.silent {
+				printWarning(err)
+			}
+		}
+	}
+}
+
+// isPlannedParentDir returns true, if dir would be created with one of its
+// subdirectories in dry run.
+func (proc *tFileProcessorCP) isPlannedParentDir(dir string) bool {
+	dirPrefix := dir + string(filepath.Separator)
+	for _, existingDir := range proc.existingDirs {
+		if strings.HasPrefix(existingDir, dirPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (proc *tFileProcessorMV) init(params *tParameters) {
+	proc.tFileProcessorCP.init(params)
+	proc.verb = "moved"
+}
+
 func (proc *tFileProcessorMV) ProcessFile(path string, info os.FileInfo, err error) error {
 	var match bool
-	if err == nil && proc.isFileNameMatch(info.Name()) {
-		match, err = proc.isContentMatch(path)
+	if err == nil && proc.isFileMatch(path, info) {
+		var found []bool
+		match, found, err = proc.isContentMatch(path)
 		if err == nil && match {
+			var outputSubDir, name string
 			subDir := path[proc.inputDirLength : len(path)-len(info.Name())]
-			outputPath := filepath.Join(proc.outputDir, subDir)
-			err = proc.ensureDir(outputPath, subDir)
+			outputSubDir, name, err = proc.targetPath(info, subDir)
+			outputPath := filepath.Join(proc.outputDir, outputSubDir)
 			if err == nil {
-				outputPath = filepath.Join(outputPath, info.Name())
-				if !check.FileExists(outputPath) {
-					err = os.Rename(path, outputPath)
-				} else {
-					err = errors.New("target file already exists: " + filepath.Join(subDir, info.Name()))
+				err = proc.ensureDir(outputPath, outputSubDir)
+			}
+			if err == nil {
+				var action int
+				outputPath, action, err = proc.resolveTarget(filepath.Join(outputPath, name), info, outputSubDir)
+				if err == nil && action == actionCOMPARE {
+					var equal bool
+					equal, err = isFileEqual(path, outputPath)
+					if equal {
+						action = actionSKIP
+					} else {
+						action = actionOVERWRITE
+					}
+				}
+				if err == nil && action != actionSKIP {
+					if proc.dryRun && action == actionOVERWRITE {
+						printOperation("overwrite", path, outputPath)
+					} else if proc.dryRun {
+						printOperation("rename", path, outputPath)
+					} else if proc.verifier != nil {
+						var sum string
+						sum, err = proc.verifier.hashFile(path)
+						if err == nil {
+							err = proc.move(journalMV, path, outputPath)
+							if err == nil {
+								err = proc.verifier.verify(outputPath, sum)
+							}
+						}
+					} else {
+						err = proc.move(journalMV, path, outputPath)
+					}
+				}
+				if err == nil {
+					proc.countAction(action)
+					proc.printFile(path, info, found, actionName(action, true), outputPath)
 				}
 			}
 		}
@@ -559,33 +1401,396 @@ func (proc *tFileProcessorMV) ProcessFile(path string, info os.FileInfo, err err
 
 func (proc *tFileProcessorPrint) ProcessFile(path string, info os.FileInfo, err error) error {
 	var match bool
-	if err == nil && proc.isFileNameMatch(info.Name()) {
-		match, err = proc.isContentMatch(path)
+	if err == nil && proc.isArchive(info.Name()) {
+		return proc.processArchive(path, func(member *tArchiveMember, found []bool) error {
+			name := path[proc.inputDirLength:] + archiveSeparator + member.name
+			if proc.linePrinter != nil {
+				return proc.printLines(name, member.reader)
+			}
+			printName(name)
+			proc.printFile(path+archiveSeparator+member.name, member.info, found, "match", "")
+			return nil
+		})
+	} else if err == nil && proc.isFileMatch(path, info) {
+		var found []bool
+		match, found, err = proc.isContentMatch(path)
 		if err == nil && match {
 			subDir := path[proc.inputDirLength : len(path)-len(info.Name())]
-			printName(filepath.Join(subDir, info.Name()))
+			if proc.linePrinter != nil {
+				var file *os.File
+				file, err = os.Open(path)
+				if err == nil {
+					err = proc.printLines(filepath.Join(subDir, info.Name()), file)
+					file.Close()
+				}
+			} else {
+				printName(filepath.Join(subDir, info.Name()))
+				proc.printFile(path, info, found, "match", "")
+			}
 		}
 	}
 	return proc.postProcess(match, err)
 }
 
+// printLines prints lines of content, that contain filter terms. name is the
+// relative path of file.
+func (proc *tFileProcessorPrint) printLines(name string, reader io.Reader) error {
+	contentReader, err := proc.contentFilter.contentReader(reader)
+	if err == nil {
+		var lines string
+		defer contentReader.Close()
+		lines, err = proc.linePrinter.lines(name, contentReader)
+		if err == nil {
+			printBlock(lines)
+		}
+	}
+	return err
+}
+
+// printSummary prints errors only. Records are followed by summary.
 func (proc *tFileProcessorPrint) printSummary(err error) {
 	if err != nil {
 		printError(err)
+	} else if records.enabled() {
+		printFinished(proc.count)
+	}
+}
+
+func (proc *tFileProcessorReport) init(params *tParameters) {
+	proc.tFileProcessorDefault.init(params)
+	proc.totals = make([]tTermTotal, len(proc.contentFilter.terms))
+}
+
+func (proc *tFileProcessorReport) ProcessFile(path string, info os.FileInfo, err error) error {
+	var match bool
+	if err == nil && proc.isArchive(info.Name()) {
+		return proc.processArchive(path, func(member *tArchiveMember, found []bool) error {
+			_, err := proc.report(path+archiveSeparator+member.name, member.info, member.reader)
+			return err
+		})
+	} else if err == nil && proc.isFileMatch(path, info) {
+		var file *os.File
+		file, err = os.Open(path)
+		if err == nil {
+			match, err = proc.report(path, info, file)
+			file.Close()
+		}
+	}
+	return proc.postProcess(match, err)
+}
+
+// report counts terms in content of file at path and prints them, if content
+// matches.
+func (proc *tFileProcessorReport) report(path string, info os.FileInfo, reader io.Reader) (bool, error) {
+	contentReader, err := proc.contentFilter.contentReader(reader)
+	if err == nil {
+		var counts []tTermCount
+		defer contentReader.Close()
+		if proc.threads {
+			buffer := bufferPool.Get().(*[]byte)
+			defer bufferPool.Put(buffer)
+			counts, err = proc.contentFilter.countTerms(contentReader, *buffer)
+		} else {
+			counts, err = proc.contentFilter.countTerms(contentReader, proc.buffer)
+		}
+		if err == nil && proc.contentFilter.isCountMatch(counts) {
+			proc.addTotals(counts)
+			printBlock(formatTermCounts(path[proc.inputDirLength:], proc.contentFilter.names, counts))
+			proc.printFile(path, info, foundTerms(counts), "match", "")
+			return true, nil
+		}
+	}
+	return false, err
+}
+
+func (proc *tFileProcessorReport) addTotals(counts []tTermCount) {
+	if proc.threads {
+		proc.mutex.Lock()
+		defer proc.mutex.Unlock()
+	}
+	for i, count := range counts {
+		if count.count > 0 {
+			proc.totals[i].files++
+			proc.totals[i].occurrences += count.count
+		}
+	}
+}
+
+func (proc *tFileProcessorReport) printSummary(err error) {
+	if err == nil {
+		printTermTotals(proc.contentFilter.names, proc.totals)
+		printFinished(proc.count)
+	} else {
+		printError(err)
+	}
+}
+
+func (proc *tFileProcessorRename) init(params *tParameters) {
+	proc.tFileProcessorDefault.init(params)
+	proc.template, _ = newRenameTemplate(params.to.Values[0], proc.nameFilter.fileName.wildcards(), proc.contentFilter.groupCount())
+	for _, part := range proc.template.parts {
+		proc.groups = proc.groups || strings.HasPrefix(part.field, "c")
+	}
+}
+
+// ProcessFile collects matching files. They are renamed in printSummary, since
+// collisions and cycles must be known before renaming.
+func (proc *tFileProcessorRename) ProcessFile(path string, info os.FileInfo, err error) error {
+	var match bool
+	if err == nil && proc.isFileMatch(path, info) {
+		var found []bool
+		match, found, err = proc.isContentMatch(path)
+		if err == nil && match {
+			var groups []string
+			if proc.groups {
+				groups, err = proc.findGroups(path)
+			}
+			if err == nil {
+				captures := proc.nameFilter.fileName.captures(filepath.ToSlash(path[proc.inputDirLength:]), info.Name())
+				rename := tRename{path: path, info: info, found: found, values: renameValues(info, captures, groups)}
+				if proc.threads {
+					proc.mutex.Lock()
+					proc.renames = append(proc.renames, rename)
+					proc.mutex.Unlock()
+				} else {
+					proc.renames = append(proc.renames, rename)
+				}
+			}
+		}
+	}
+	return proc.postProcess(match, err)
+}
+
+// findGroups returns capture groups of content filter in file at path.
+func (proc *tFileProcessorRename) findGroups(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err == nil {
+		defer file.Close()
+		var contentReader io.ReadCloser
+		contentReader, err = proc.contentFilter.contentReader(file)
+		if err == nil {
+			defer contentReader.Close()
+			if proc.threads {
+				buffer := bufferPool.Get().(*[]byte)
+				defer bufferPool.Put(buffer)
+				return proc.contentFilter.findGroups(contentReader, *buffer)
+			}
+			return proc.contentFilter.findGroups(contentReader, proc.buffer)
+		}
+	}
+	return nil, err
+}
+
+func (proc *tFileProcessorRename) printSummary(err error) {
+	var renamed int
+	if err == nil {
+		renamed, err = proc.renameFiles()
+	}
+	err = proc.closeJournal(err)
+	if err == nil {
+		printFinishedSummary(proc.count, strconv.Itoa(renamed)+" renamed, "+strconv.Itoa(proc.count-renamed)+" unchanged")
+	} else {
+		printError(err)
+	}
+}
+
+// renameFiles renames collected files, if there are no collisions and cycles.
+// Returns number of renamed files.
+func (proc *tFileProcessorRename) renameFiles() (int, error) {
+	var renamed int
+	ordered, errs := planRenames(proc.renames, proc.template)
+	if len(errs) == 0 {
+		for _, rename := range ordered {
+			var err error
+			if proc.dryRun {
+				printOperation("rename", rename.path, rename.target)
+			} else if isSameFileOrFree(rename.path, rename.target) {
+				err = proc.move(journalRENAME, rename.path, rename.target)
+			} else {
+				// created by another process
+				err = errors.New("target file already exists: " + rename.target)
+			}
+			if err == nil {
+				renamed++
+				proc.printFile(rename.path, rename.info, rename.found, "rename", rename.target)
+			} else if !proc.silent {
+				printWarning(err)
+			}
+		}
+		for _, rename := range proc.renames {
+			if rename.target == rename.path {
+				proc.printFile(rename.path, rename.info, rename.found, "unchanged", "")
+			}
+		}
+		return renamed, nil
+	}
+	for _, err := range errs {
+		printWarning(err)
+	}
+	return 0, errors.New("no file renamed, conflicts: " + strconv.Itoa(len(errs)))
+}
+
+func (proc *tFileProcessorReplace) init(params *tParameters) {
+	proc.tFileProcessorDefault.init(params)
+	proc.replacer, _ = newReplacer(params)
+	proc.diff = params.diff.Available()
+	if params.backup.Available() {
+		proc.backupSuffix = params.backup.Values[0]
+		if len(proc.backupSuffix) == 0 {
+			proc.backupSuffix = backupSuffixDEFAULT
+		}
+	}
+	// like sed -i replace keeps permissions
+	proc.preserve = preserveMODE
+	if params.preserve.Available() {
+		preserve, _ := parsePreserve(params.preserve.Values[0])
+		proc.preserve |= preserve
+	}
+}
+
+func (proc *tFileProcessorReplace) ProcessFile(path string, info os.FileInfo, err error) error {
+	var match bool
+	if err == nil && proc.isFileMatch(path, info) {
+		var found []bool
+		match, found, err = proc.isContentMatch(path)
+		if err == nil && match && !info.Mode().IsRegular() {
+			err = errors.New("not a regular file: " + path)
+		} else if err == nil && match {
+			var data []byte
+			data, err = os.ReadFile(path)
+			if err == nil && hasUTF16BOM(data) {
+				// filter matches decoded content, but replacement works on raw content
+				err = errors.New("UTF-16 content is not supported by replace: " + path)
+			} else if err == nil {
+				replaced, replacements := proc.replacer.replace(data)
+				changed := !bytes.Equal(data, replaced)
+				if changed && proc.diff {
+					relPath := filepath.ToSlash(path[proc.inputDirLength:])
+					printBlock(unifiedDiff("a/"+relPath, "b/"+relPath, data, replaced))
+				} else if changed && proc.dryRun {
+					printOperation("replace", path)
+				} else if changed {
+					err = proc.writeFile(path, info, data, replaced)
+				}
+				if err == nil {
+					proc.countReplacements(changed, replacements)
+					if changed {
+						proc.printFile(path, info, found, "replace", path)
+					} else {
+						proc.printFile(path, info, found, "unchanged", "")
+					}
+				}
+			}
+		}
+	}
+	return proc.postProcess(match, err)
+}
+
+// writeFile replaces content of file at path. Backup is written first, so
+// that original content is never lost.
+func (proc *tFileProcessorReplace) writeFile(path string, info os.FileInfo, data, replaced []byte) error {
+	var err error
+	if len(proc.backupSuffix) > 0 {
+		err = writeAtomic(path+proc.backupSuffix, path, info, data, proc.preserve|preserveTIMESTAMPS)
+	}
+	if err == nil {
+		err = writeAtomic(path, path, info, replaced, proc.preserve)
+	}
+	return err
+}
+
+func (proc *tFileProcessorReplace) countReplacements(changed bool, replacements int) {
+	if proc.threads {
+		proc.mutex.Lock()
+		defer proc.mutex.Unlock()
+	}
+	if changed {
+		proc.changed++
+	}
+	proc.replacements += replacements
+}
+
+func (proc *tFileProcessorReplace) printSummary(err error) {
+	if err != nil {
+		printError(err)
+	} else if !proc.diff {
+		summary := strconv.Itoa(proc.changed) + " changed, " + strconv.Itoa(proc.count-proc.changed) + " unchanged, "
+		printFinishedSummary(proc.count, summary+strconv.Itoa(proc.replacements)+" replacements")
 	}
 }
 
 func (proc *tFileProcessorRM) ProcessFile(path string, info os.FileInfo, err error) error {
 	var match bool
-	if err == nil && proc.isFileNameMatch(info.Name()) {
-		match, err = proc.isContentMatch(path)
+	if err == nil && proc.isFileMatch(path, info) {
+		var found []bool
+		match, found, err = proc.isContentMatch(path)
 		if err == nil && match {
-			err = os.Remove(path)
+			if proc.dryRun && proc.useTrash {
+				printOperation("trash", path)
+			} else if proc.dryRun {
+				printOperation("remove", path)
+			} else if proc.trash != nil {
+				err = proc.moveToTrash(path)
+			} else if proc.journal != nil {
+				trashPath := filepath.Join(proc.trashDir, path[proc.inputDirLength:])
+				err = os.MkdirAll(filepath.Dir(trashPath), 0777)
+				if err == nil {
+					err = proc.move(journalRM, path, trashPath)
+				}
+			} else {
+				err = os.Remove(path)
+			}
+			if err == nil && proc.useTrash {
+				proc.printFile(path, info, found, "trash", "")
+			} else if err == nil {
+				proc.printFile(path, info, found, "remove", "")
+			}
 		}
 	}
 	return proc.postProcess(match, err)
 }
 
+func (proc *tFileProcessorRM) init(params *tParameters) {
+	proc.tFileProcessorDefault.init(params)
+	proc.trash = params.trashBin
+	proc.useTrash = params.isTrash()
+	if proc.journal != nil {
+		proc.trashDir = proc.journal.trashDir(time.Now())
+	}
+}
+
+// moveToTrash moves file to trash and logs it in journal, if available.
+func (proc *tFileProcessorRM) moveToTrash(path string) error {
+	var hash string
+	var err error
+	if proc.journal != nil {
+		hash, err = fileHash(path)
+	}
+	if err == nil {
+		var trashPath string
+		trashPath, err = proc.trash.move(path)
+		if err == nil && proc.journal != nil {
+			err = proc.journal.write(journalRM, path, trashPath, hash)
+		}
+	}
+	return err
+}
+
+// move renames file and logs it in journal, if available.
+func (proc *tFileProcessorDefault) move(operation, source, destination string) error {
+	if proc.journal != nil {
+		hash, err := fileHash(source)
+		if err == nil {
+			err = renameFile(source, destination)
+			if err == nil {
+				err = proc.journal.write(operation, source, destination, hash)
+			}
+		}
+		return err
+	}
+	return renameFile(source, destination)
+}
+
 func hasPrefix(str string, prefix []byte, offset int) bool {
 	if len(str) >= len(prefix) {
 		for i := 0; i+offset <= len(str); i++ {
@@ -601,25 +1806,6 @@ func hasPrefix(str string, prefix []byte, offset int) bool {
 	return false
 }
 
-func pathSeparator(path string) byte {
-	for i := len(path) - 1; i >= 0; i-- {
-		b := path[i]
-		if b == '/' || b == '\\' {
-			return b
-		}
-	}
-	return filepath.Separator
-}
-
-func rindex(str string, b byte) int {
-	for i := len(str) - 1; i >= 0; i-- {
-		if str[i] == b {
-			return i
-		}
-	}
-	return -1
-}
-
 func dirLengthWOEndingSeparator(path string) int {
 	if b := path[len(path)-1]; b == '/' || b == '\\' {
 		return len(path) - 1
@@ -627,14 +1813,6 @@ func dirLengthWOEndingSeparator(path string) int {
 	return len(path)
 }
 
-func toBytes(strings []string) [][]byte {
-	terms := make([][]byte, len(strings))
-	for i, term := range strings {
-		terms[i] = []byte(term)
-	}
-	return terms
-}
-
 func printInfo(params *tParameters) {
 	if params.help == nil {
 		printShortInfo()
@@ -659,21 +1837,105 @@ func printHelp() {
 	message := "\nUSAGE\n"
 	message += "  fbc (INFO | ( COMMAND INPUT-DIR {OUTPUT-DIR FILTER OPTION} ))\n\n"
 	message += "INFO\n"
-	message += "  -h, --help       print this help\n"
-	message += "  -v, --version    print version\n"
-	message += "  -e, --example    print example\n"
-	message += "  -c, --copyright  print copyright\n"
+	message += "  -h, --help              print this help\n"
+	message += "  -v, --version           print version\n"
+	message += "  -e, --example           print example\n"
+	message += "  -c, --copyright         print copyright\n"
 	message += "COMMAND\n"
-	message += "  count            count files\n"
-	message += "  cp               copy files\n"
-	message += "  mv               move files\n"
-	message += "  print            print file names\n"
-	message += "  rm               delete files\n"
+	message += "  count                   count files\n"
+	message += "  cp                      copy files\n"
+	message += "  mv                      move files\n"
+	message += "  print                   print file names\n"
+	message += "  rename                  rename files in place (see --to)\n"
+	message += "  replace                 replace content of files (see --find and --with)\n"
+	message += "  report                  print found filter terms per file and files per term\n"
+	message += "  rm                      delete files\n"
+	message += "  undo                    undo mv, rename and rm logged in journal (INPUT-DIR is\n"
+	message += "                          journal)\n"
 	message += "OPTION\n"
-	message += "  -o, --or         filter is OR (not AND)\n"
-	message += "  -r, --recursive  recursive file iteration\n"
-	message += "  -s, --silent     don't output errors to screen when reading files\n"
-	message += "  -t, --threads    use threads"
+	message += "  -0, --print0            print terminates file names with NUL (not newline);\n"
+	message += "                          warnings and errors are printed to stderr\n"
+	message += "  -a, --archives          process zip, tar and tar.gz files like directories\n"
+	message += "                          (not with mv, rename and rm)\n"
+	message += "  -A, --after-context=N   print prints N lines after matching lines (implies\n"
+	message += "                          --lines)\n"
+	message += "      --backup[=SUFFIX]   replace keeps original files with SUFFIX (.bak)\n"
+	message += "  -b, --boolean           filter is expression with AND, OR, NOT, ( ) and \"terms\"\n"
+	message += "  -B, --before-context=N  print prints N lines before matching lines (implies\n"
+	message += "                          --lines)\n"
+	message += "  -C, --context=N         print prints N lines before and after matching lines\n"
+	message += "                          (implies --lines)\n"
+	message += "      --color[=WHEN]      print highlights matches: auto (default), always or\n"
+	message += "                          never (implies --lines)\n"
+	message += "      --dest-template=TMPL cp and mv build target paths in OUTPUT-DIR from TMPL\n"
+	message += "                          with {name}, {base}, {ext}, {dir}, {year}, {month}\n"
+	message += "                          and {day}, e.g. {year}/{month}/{ext}/{name}\n"
+	message += "      --diff              replace prints unified diff instead of changing files\n"
+	message += "      --encoding=ENC      content encoding (auto, utf-8, utf-16le, utf-16be,\n"
+	message += "                          latin-1, windows-1252); auto detects UTF-16 BOM\n"
+	message += "      --exclude=GLOB      skip files with matching name (repeatable)\n"
+	message += "      --exclude-dir=GLOB  skip directories with matching name (repeatable)\n"
+	message += "      --executable        process only files with execute permission\n"
+	message += "      --files-from=FILE   process files listed in FILE (- is stdin) instead of\n"
+	message += "                          iterating INPUT-DIR; paths are separated by NUL or\n"
+	message += "                          newline and relative to INPUT-DIR (not with -r, -g)\n"
+	message += "      --find=PATTERN      replace substitutes PATTERN (regular expression with\n"
+	message += "                          -x, ignoring case with -i) in UTF-8 content\n"
+	message += "      --flatten           cp and mv put all files directly into OUTPUT-DIR; equal\n"
+	message += "                          names are numbered in order of paths like \"a (1).txt\"\n"
+	message += "                          (without threads)\n"
+	message += "      --format=FORMAT     output: text (default), jsonl or csv; records of files,\n"
+	message += "                          terms, warnings, errors and summary with type, path,\n"
+	message += "                          rel_path, size, mtime, terms, action, dry_run,\n"
+	message += "                          target, error, count and message (not with --lines and --diff)\n"
+	message += "  -g, --ignore-files      skip files listed in .gitignore, .ignore and .fbcignore\n"
+	message += "  -i, --ignore-case       filter ignores case (Unicode)\n"
+	message += "      --include=GLOB      process only files with matching name (repeatable)\n"
+	message += "      --include-dir=GLOB  enter only directories with matching name (repeatable)\n"
+	message += "      --journal=FILE      log mv, rename and rm in FILE for undo; rm moves\n"
+	message += "                          files to directory FILE.trash instead of deleting them\n"
+	message += "      --lines             print prints matching lines like grep (FILE:LINE:\n"
+	message += "                          COLUMN:TEXT) of files passing the whole filter\n"
+	message += "      --manifest          cp and mv write checksums of verified files to OUTPUT-DIR\n"
+	message += "                          (SHA256SUMS, B2SUMS or XXH64SUMS)\n"
+	message += "      --newer=TIME        process only files modified after TIME (2026-01-01,\n"
+	message += "                          2026-01-01T12:00:00, or age like 30d, 12h, 2w)\n"
+	message += "      --older=TIME        process only files modified before TIME\n"
+	message += "  -n, --dry-run           print operations of cp, mv, rename, replace and rm\n"
+	message += "                          without executing them\n"
+	message += "      --no-trash          rm deletes files permanently, even if FBC_TRASH is set\n"
+	message += "  -o, --or                filter is OR (not AND)\n"
+	message += "      --overwrite=POLICY  existing targets of cp and mv: never (default), always,\n"
+	message += "                          newer, larger or different (content)\n"
+	message += "      --perm=PERM         process only files with permissions PERM (0644), all\n"
+	message += "                          bits of PERM (-0600) or any bit of PERM (/0111)\n"
+	message += "  -p, --preserve[=LIST]   cp and replace keep mode, timestamps and ownership of\n"
+	message += "                          files and directories; LIST is a selection of mode,\n"
+	message += "                          timestamps, ownership, xattr or all (comma separated);\n"
+	message += "                          replace keeps mode always\n"
+	message += "  -r, --recursive         recursive file iteration\n"
+	message += "      --rename            cp and mv use unique name like \"a (1).txt\", if target\n"
+	message += "                          exists\n"
+	message += "  -s, --silent            don't output errors to screen when reading files\n"
+	message += "      --size=SIZE         process only files of size greater (+10M), less (-1k)\n"
+	message += "                          or equal (512) SIZE; units k, M, G, T (repeatable)\n"
+	message += "  -t, --threads           use threads\n"
+	message += "      --to=TMPL           rename builds new file names from TMPL with {name},\n"
+	message += "                          {base}, {ext}, {year}, {month}, {day}, {date}, counter\n"
+	message += "                          {n} or {n:3} (zero padded), wildcards of file name\n"
+	message += "                          filter {1}, {2}, ... and capture groups of regular\n"
+	message += "                          expressions {c1}, {c2}, ... (first match in file)\n"
+	message += "      --trash             rm moves files to trash (default, if environment\n"
+	message += "                          variable FBC_TRASH is 1, true or yes)\n"
+	message += "      --verify[=HASH]     cp and mv compare checksums of source and target; HASH\n"
+	message += "                          is sha256 (default), blake2b or xxh64\n"
+	message += "      --with=STR          replacement for --find; with -x $1 or ${name} refer\n"
+	message += "                          to capture groups\n"
+	message += "  -x, --regex             filter terms are regular expressions (anchors ^, $, \\A\n"
+	message += "                          \\z, \\b and \\B are not supported; terms must not match\n"
+	message += "                          empty content)\n"
+	message += "  -z, --decompress        filter content of gzip, bzip2, xz and zstd files\n"
+	message += "                          decompressed (xz and zstd need programs installed)"
 	fmt.Println(message)
 }
 
@@ -688,35 +1950,122 @@ func printCopyright() {
 }
 
 func printCount(count int) {
-	fmt.Println(count)
+	if records.enabled() {
+		records.write(&tRecord{Type: recordSUMMARY, Count: count})
+	} else {
+		fmt.Println(count)
+	}
 }
 
+// printName prints name of file. With records files are written by printFile.
 func printName(name string) {
-	fmt.Println(name)
+	if print0 {
+		fmt.Print(name + "\x00")
+	} else if !records.enabled() {
+		fmt.Println(name)
+	}
+}
+
+// printOperation prints file operation in dry run.
+func printOperation(operation string, paths ...string) {
+	if !records.enabled() {
+		fmt.Println(operation + " " + strings.Join(paths, " -> "))
+	}
+}
+
+// printBlock prints output of one file at once, so that output of threads
+// doesn't mix.
+func printBlock(text string) {
+	if !records.enabled() {
+		fmt.Print(text)
+	}
+}
+
+// printTermTotals prints files and occurrences per term. Term records have
+// number of files in count.
+func printTermTotals(names []string, totals []tTermTotal) {
+	if records.enabled() {
+		for i, total := range totals {
+			records.write(&tRecord{Type: recordTERM, Terms: []string{names[i]}, Count: total.files, Message: strconv.Itoa(total.occurrences) + " occurrences"})
+		}
+	} else {
+		fmt.Print(formatTermTotals(names, totals))
+	}
 }
 
 func printFinished(count int) {
-	var fileStr string
-	if count == 1 {
-		fileStr = " file"
+	if records.enabled() {
+		records.write(&tRecord{Type: recordSUMMARY, Count: count})
 	} else {
-		fileStr = " files"
+		fmt.Println("finished: " + filesStr(count))
 	}
-	fmt.Println("finished: " + strconv.Itoa(count) + fileStr)
+}
+
+// printFinishedSummary prints number of files with summary in parentheses.
+func printFinishedSummary(count int, summary string) {
+	if records.enabled() {
+		records.write(&tRecord{Type: recordSUMMARY, Count: count, Message: summary})
+	} else {
+		fmt.Println("finished: " + filesStr(count) + " (" + summary + ")")
+	}
+}
+
+func filesStr(count int) string {
+	if count == 1 {
+		return strconv.Itoa(count) + " file"
+	}
+	return strconv.Itoa(count) + " files"
 }
 
 func printError(err error) {
-	fmt.Println("error: " + err.Error())
+	if print0 {
+		fmt.Fprintln(os.Stderr, "error: "+err.Error())
+	} else if records.enabled() {
+		records.write(&tRecord{Type: recordERROR, Error: err.Error()})
+	} else {
+		fmt.Println("error: " + err.Error())
+	}
 }
 
 func printWarning(err error) {
-	fmt.Println("warning: " + err.Error())
+	if print0 {
+		fmt.Fprintln(os.Stderr, "warning: "+err.Error())
+	} else if records.enabled() {
+		records.write(&tRecord{Type: recordWARNING, Error: err.Error()})
+	} else {
+		fmt.Println("warning: " + err.Error())
+	}
 }
 
 func printExample() {
 	message := "\nEXAMPLES\n"
 	message += "   fbc cp ./ ../bak bob alice\n"
 	message += "   fbc mv \"./*.txt\" ../bak bob alice\n"
-	message += "   fbc rm \"./*.txt\" bob alice"
+	message += "   fbc rm \"./*.txt\" bob alice\n"
+	message += "   fbc print -x ./ \"INV-[0-9]{6}\"\n"
+	message += "   fbc print -b ./ \"(alice OR bob) AND NOT draft\"\n"
+	message += "   fbc print -r -z ./logs alice\n"
+	message += "   fbc cp -a ./ ../extracted alice\n"
+	message += "   fbc print -r -g ./ alice\n"
+	message += "   fbc print -r -C 2 --color ./ alice bob\n"
+	message += "   fbc report -r -o ./ alice bob\n"
+	message += "   fbc cp -r --format=jsonl ./ ../bak alice bob\n"
+	message += "   git ls-files -z | fbc cp --files-from=- ./ ../bak alice\n"
+	message += "   fbc print -0 -r ./ alice | fbc rm --files-from=- ./ bob\n"
+	message += "   fbc rm -r ./logs --older=30d --size=+1M host42\n"
+	message += "   fbc mv -n -r ./ ../bak alice\n"
+	message += "   fbc mv -r --journal=../mv.journal ./ ../bak alice\n"
+	message += "   fbc undo ../mv.journal\n"
+	message += "   fbc rm --trash \"./*.txt\" alice\n"
+	message += "   fbc cp -r --overwrite=newer ./ ../bak alice\n"
+	message += "   fbc cp -r --preserve=all ./ ../bak alice\n"
+	message += "   fbc cp -r --verify --manifest ./ ../bak alice\n"
+	message += "   fbc cp -r --dest-template={year}/{month}/{ext}/{name} ./ ../sorted alice\n"
+	message += "   fbc rename -x \"./scan-*.pdf\" --to=\"{1}-{c1}.pdf\" \"INV-([0-9]{6})\"\n"
+	message += "   fbc rename \"./IMG_*.jpg\" --to=\"{date}-{n:3}.jpg\"\n"
+	message += "   fbc replace -r ./ --find=alice --with=bob --backup alice\n"
+	message += "   fbc replace -r -x --diff \"./*.go\" --find=\"v([0-9]+)\\.0\" --with='v${1}.1' \"v[0-9]+\\.0\"\n"
+	message += "   fbc print \"./src/**/report-202[34]-??.{csv,txt}\" alice\n"
+	message += "   fbc print -r ./ --include=\"*.go\" --include=\"*.md\" --exclude=\"*_test.go\" alice"
 	fmt.Println(message)
 }
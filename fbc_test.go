@@ -123,4 +123,140 @@ func TestParseOSArgsC(t *testing.T) {
 	} else if params.fileNameFilter != "*.txt" {
 		t.Error(params.fileNameFilter)
 	}
+
+	args.Values = []string{"count", "./**/*.{go,md}"}
+	args.Parsed = make([]bool, len(args.Values))
+	params = new(tParameters)
+	err = params.initFromArgs(args)
+	if err != nil {
+		t.Error(err.Error())
+	} else if params.fileNameFilter != "**/*.{go,md}" {
+		t.Error(params.fileNameFilter)
+	} else if !params.isRecursive() {
+		t.Error("recursive file name filter not recognized")
+	}
+
+	// brackets in existing directories are no wildcards
+	dir := filepath.Join(t.TempDir(), "g[1]")
+	os.MkdirAll(filepath.Join(dir, "{x}"), 0777)
+	for path, fileNameFilter := range map[string]string{dir: "*", filepath.Join(dir, "{x}"): "*", filepath.Join(dir, "*.txt"): "*.txt", filepath.Join(dir, "{x,y}", "*.txt"): "{x,y}/*.txt"} {
+		args.Values = []string{"count", path}
+		args.Parsed = make([]bool, len(args.Values))
+		params = new(tParameters)
+		err = params.initFromArgs(args)
+		if err != nil {
+			t.Error(err.Error())
+		} else if params.fileNameFilter != fileNameFilter {
+			t.Error(path, params.fileNameFilter)
+		} else if inputDir := params.input.Values[0]; inputDir != dir && inputDir != filepath.Join(dir, "{x}") {
+			t.Error(path, inputDir)
+		}
+	}
+
+	args.Values = []string{"count", "./*.[a-"}
+	args.Parsed = make([]bool, len(args.Values))
+	params = new(tParameters)
+	err = params.initFromArgs(args)
+	if err == nil {
+		t.Error("malformed file name filter not recognized")
+	}
+}
+
+func TestParseOSArgsD(t *testing.T) {
+	args := new(osargs.Arguments)
+	args.Values = []string{"print", ".", "--include=*.go", "--include", "*.md", "--exclude=*_test.go", "--exclude-dir=vendor", "alice"}
+	args.Parsed = make([]bool, len(args.Values))
+	params := new(tParameters)
+	err := params.initFromArgs(args)
+	if err != nil {
+		t.Error(err.Error())
+	} else if len(params.contentFilter) != 1 || params.contentFilter[0] != "alice" {
+		t.Error(params.contentFilter)
+	} else {
+		filter := params.nameFilter
+		if !filter.isFileMatch("fbc.go", "fbc.go") || !filter.isFileMatch("README.md", "README.md") {
+			t.Error("included file name not recognized")
+		}
+		if filter.isFileMatch("fbc_test.go", "fbc_test.go") || filter.isFileMatch("LICENSE", "LICENSE") {
+			t.Error("excluded file name not recognized")
+		}
+		if filter.isDirMatch("vendor", "vendor") || !filter.isDirMatch("src", "src") {
+			t.Error("excluded directory not recognized")
+		}
+	}
+}
+
+func TestParseOSArgsE(t *testing.T) {
+	// long option names without dashes are filter terms
+	for _, term := range []string{"regex", "boolean", "ignore-case", "decompress", "archives", "ignore-files", "executable", "dry-run", "trash", "no-trash", "manifest", "flatten", "diff", "print0"} {
+		args := new(osargs.Arguments)
+		args.Values = []string{"count", ".", term}
+		args.Parsed = make([]bool, len(args.Values))
+		params := new(tParameters)
+		err := params.initFromArgs(args)
+		if err != nil {
+			t.Error(err.Error())
+		} else if len(params.contentFilter) != 1 || params.contentFilter[0] != term {
+			t.Error(term, params.contentFilter)
+		}
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	_, inputDir, outputDir := newTestDirs(t, map[string]string{"in/sub/a.txt": "alice"})
+	for _, command := range []string{"cp", "mv", "rm"} {
+		args := new(osargs.Arguments)
+		if command == "rm" {
+			args.Values = []string{command, "-n", "-r", inputDir, "alice"}
+		} else {
+			args.Values = []string{command, "-n", "-r", inputDir, outputDir, "alice"}
+		}
+		args.Parsed = make([]bool, len(args.Values))
+		params := new(tParameters)
+		err := params.initFromArgs(args)
+		if err == nil {
+			proc := newFileProcessor(params)
+			err = iterateFiles(params, proc)
+		}
+		if err != nil {
+			t.Error(err.Error())
+		}
+	}
+	_, err := os.Stat(filepath.Join(inputDir, "sub", "a.txt"))
+	if err != nil {
+		t.Error(err.Error())
+	}
+	_, err = os.Stat(filepath.Join(outputDir, "sub"))
+	if err == nil {
+		t.Error("directory created in dry run")
+	}
+}
+
+// newTestDirs returns temporary directory with subdirectories in and out and
+// writes files into it (see writeTestFiles).
+func newTestDirs(t *testing.T, files map[string]string) (string, string, string) {
+	dir := t.TempDir()
+	inputDir, outputDir := filepath.Join(dir, "in"), filepath.Join(dir, "out")
+	for _, path := range []string{inputDir, outputDir} {
+		if err := os.MkdirAll(path, 0777); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	writeTestFiles(t, dir, files)
+	return dir, inputDir, outputDir
+}
+
+// writeTestFiles writes files with content to dir. Names of files are slash
+// separated and relative to dir. Parent directories are created.
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(path), 0777)
+		if err == nil {
+			err = os.WriteFile(path, []byte(content), 0666)
+		}
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
 }
@@ -0,0 +1,464 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	globLITERAL = iota
+	globANY
+	globCLASS
+	globSTAR
+	// globDIRS matches "**/", i.e. zero or more directories
+	globDIRS
+	// globREST matches "**" at the end of pattern, i.e. everything
+	globREST
+)
+
+// maxGlobAlternatives limits expansion of brace sets.
+const maxGlobAlternatives = 1024
+
+// tGlob is a file name pattern. Supported are *, ?, character classes like [a-z]
+// and [!a-z], brace sets like {txt,md} and ** for any number of directories.
+// Patterns containing / are matched against path relative to input directory.
+type tGlob struct {
+	alternatives [][]tGlobToken
+	path         bool
+}
+
+type tGlobToken struct {
+	kind    int
+	literal string
+	ranges  []rune
+	negate  bool
+}
+
+// tNameFilter matches names and relative paths of files and directories.
+type tNameFilter struct {
+	fileName    *tGlob
+	includes    []*tGlob
+	excludes    []*tGlob
+	includeDirs []*tGlob
+	excludeDirs []*tGlob
+}
+
+func newNameFilter(params *tParameters) (*tNameFilter, error) {
+	var err error
+	filter := new(tNameFilter)
+	filter.fileName, err = newGlob(params.fileNameFilter)
+	if err == nil {
+		filter.includes, err = newGlobs(params.include.Values)
+		if err == nil {
+			filter.excludes, err = newGlobs(params.exclude.Values)
+			if err == nil {
+				filter.includeDirs, err = newGlobs(params.includeDir.Values)
+				if err == nil {
+					filter.excludeDirs, err = newGlobs(params.excludeDir.Values)
+				}
+			}
+		}
+	}
+	return filter, err
+}
+
+// isFileMatch returns true, if file passes name filters. relPath is relative to input directory.
+func (filter *tNameFilter) isFileMatch(relPath, name string) bool {
+	relPath = filepath.ToSlash(relPath)
+	if filter.fileName.match(relPath, name) {
+		if len(filter.includes) == 0 || matchAny(filter.includes, relPath, name) {
+			return !matchAny(filter.excludes, relPath, name)
+		}
+	}
+	return false
+}
+
+// isDirMatch returns true, if directory passes name filters. relPath is relative to input directory.
+func (filter *tNameFilter) isDirMatch(relPath, name string) bool {
+	relPath = filepath.ToSlash(relPath)
+	if len(filter.includeDirs) == 0 || matchAny(filter.includeDirs, relPath, name) {
+		return !matchAny(filter.excludeDirs, relPath, name)
+	}
+	return false
+}
+
+func newGlob(pattern string) (*tGlob, error) {
+	patterns, err := expandBraces(pattern)
+	if err == nil {
+		glob := new(tGlob)
+		glob.alternatives = make([][]tGlobToken, 0, len(patterns))
+		for _, patternExpanded := range patterns {
+			var tokens []tGlobToken
+			tokens, err = compileGlob(patternExpanded)
+			if err == nil {
+				glob.alternatives = append(glob.alternatives, tokens)
+				glob.path = glob.path || strings.ContainsRune(patternExpanded, '/')
+			} else {
+				return nil, errors.New("wrong pattern " + pattern + ": " + err.Error())
+			}
+		}
+		return glob, nil
+	}
+	return nil, errors.New("wrong pattern " + pattern + ": " + err.Error())
+}
+
+func newGlobs(patterns []string) ([]*tGlob, error) {
+	globs := make([]*tGlob, len(patterns))
+	for i, pattern := range patterns {
+		var err error
+		globs[i], err = newGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return globs, nil
+}
+
+// isGlob returns true, if str contains wildcards.
+func isGlob(str string) bool {
+	return strings.ContainsAny(str, "*?[{")
+}
+
+// expandBraces returns all alternatives of pattern, e.g. *.{txt,md} returns *.txt and *.md.
+func expandBraces(pattern string) ([]string, error) {
+	begin, end, commas, err := findBraces(pattern)
+	if err == nil && begin >= 0 {
+		var patterns []string
+		prefix, suffix := pattern[:begin], pattern[end+1:]
+		commas = append(commas, end)
+		for i, offset := 0, begin+1; i < len(commas); i++ {
+			var expanded []string
+			expanded, err = expandBraces(prefix + pattern[offset:commas[i]] + suffix)
+			if err == nil {
+				patterns = append(patterns, expanded...)
+				if len(patterns) > maxGlobAlternatives {
+					return nil, errors.New("too many alternatives")
+				}
+			} else {
+				return nil, err
+			}
+			offset = commas[i] + 1
+		}
+		return patterns, nil
+	}
+	return []string{pattern}, err
+}
+
+// findBraces returns position of first brace set and of its top level commas.
+func findBraces(pattern string) (int, int, []int, error) {
+	var commas []int
+	begin, depth := -1, 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			// braces in character class are literals
+			end := classEnd(pattern, i)
+			if end > i {
+				i = end
+			}
+		case '{':
+			if depth == 0 {
+				begin = i
+			}
+			depth++
+		case ',':
+			if depth == 1 {
+				commas = append(commas, i)
+			}
+		case '}':
+			if depth == 1 {
+				return begin, i, commas, nil
+			} else if depth > 1 {
+				depth--
+			}
+		}
+	}
+	if depth > 0 {
+		return -1, -1, nil, errors.New("missing }")
+	}
+	return -1, -1, nil, nil
+}
+
+// classEnd returns position of ] closing the character class beginning at begin, or -1.
+func classEnd(pattern string, begin int) int {
+	i := begin + 1
+	if i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^') {
+		i++
+	}
+	if i < len(pattern) && pattern[i] == ']' {
+		i++
+	}
+	for ; i < len(pattern); i++ {
+		if pattern[i] == '\\' {
+			i++
+		} else if pattern[i] == ']' {
+			return i
+		}
+	}
+	return -1
+}
+
+func compileGlob(pattern string) ([]tGlobToken, error) {
+	var tokens []tGlobToken
+	var literal []byte
+	for i := 0; i < len(pattern); i++ {
+		b := pattern[i]
+		if b == '*' || b == '?' || b == '[' {
+			if len(literal) > 0 {
+				tokens = append(tokens, tGlobToken{kind: globLITERAL, literal: string(literal)})
+				literal = literal[:0]
+			}
+		}
+		switch b {
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				literal = append(literal, pattern[i])
+			} else {
+				return nil, errors.New("\\ at end")
+			}
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' && (i == 0 || pattern[i-1] == '/') {
+				if i+2 == len(pattern) {
+					tokens = append(tokens, tGlobToken{kind: globREST})
+					i++
+				} else if pattern[i+2] == '/' {
+					tokens = append(tokens, tGlobToken{kind: globDIRS})
+					i += 2
+				} else {
+					tokens = append(tokens, tGlobToken{kind: globSTAR})
+				}
+			} else if len(tokens) == 0 || tokens[len(tokens)-1].kind != globSTAR {
+				tokens = append(tokens, tGlobToken{kind: globSTAR})
+			}
+		case '?':
+			tokens = append(tokens, tGlobToken{kind: globANY})
+		case '[':
+			token, end, err := compileClass(pattern, i)
+			if err == nil {
+				tokens = append(tokens, token)
+				i = end
+			} else {
+				return nil, err
+			}
+		default:
+			literal = append(literal, b)
+		}
+	}
+	if len(literal) > 0 {
+		tokens = append(tokens, tGlobToken{kind: globLITERAL, literal: string(literal)})
+	}
+	return tokens, nil
+}
+
+func compileClass(pattern string, begin int) (tGlobToken, int, error) {
+	var token tGlobToken
+	end := classEnd(pattern, begin)
+	if end > begin {
+		i := begin + 1
+		token.kind = globCLASS
+		if pattern[i] == '!' || pattern[i] == '^' {
+			token.negate = true
+			i++
+		}
+		for i < end {
+			var lo, hi rune
+			lo, i = classRune(pattern, i)
+			hi = lo
+			if i+1 < end && pattern[i] == '-' {
+				hi, i = classRune(pattern, i+1)
+				if hi < lo {
+					return token, end, errors.New("wrong range in character class")
+				}
+			}
+			token.ranges = append(token.ranges, lo, hi)
+		}
+		if len(token.ranges) > 0 {
+			return token, end, nil
+		}
+		return token, end, errors.New("empty character class")
+	}
+	return token, end, errors.New("missing ]")
+}
+
+func classRune(pattern string, i int) (rune, int) {
+	if pattern[i] == '\\' {
+		i++
+	}
+	r, size := utf8.DecodeRuneInString(pattern[i:])
+	return r, i + size
+}
+
+// match returns true, if relPath (or name, if pattern has no /) matches.
+func (glob *tGlob) match(relPath, name string) bool {
+	if !glob.path {
+		relPath = name
+	}
+	for _, tokens := range glob.alternatives {
+		if matchTokens(tokens, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// captures returns strings matched by wildcards of the first matching
+// alternative, e.g. pattern *-*.txt and name a-b-c.txt return a and b-c.
+func (glob *tGlob) captures(relPath, name string) []string {
+	if !glob.path {
+		relPath = name
+	}
+	for _, tokens := range glob.alternatives {
+		if captures, ok := captureTokens(tokens, relPath, nil); ok {
+			return captures
+		}
+	}
+	return nil
+}
+
+// wildcards returns the maximum number of wildcards of alternatives.
+func (glob *tGlob) wildcards() int {
+	var count int
+	for _, tokens := range glob.alternatives {
+		var countAlt int
+		for _, token := range tokens {
+			if token.kind != globLITERAL {
+				countAlt++
+			}
+		}
+		if countAlt > count {
+			count = countAlt
+		}
+	}
+	return count
+}
+
+func matchTokens(tokens []tGlobToken, str string) bool {
+	for i := 0; i < len(tokens); i++ {
+		token := &tokens[i]
+		switch token.kind {
+		case globLITERAL:
+			if strings.HasPrefix(str, token.literal) {
+				str = str[len(token.literal):]
+			} else {
+				return false
+			}
+		case globANY, globCLASS:
+			if len(str) > 0 && str[0] != '/' {
+				r, size := utf8.DecodeRuneInString(str)
+				if token.kind == globANY || token.matchRune(r) {
+					str = str[size:]
+				} else {
+					return false
+				}
+			} else {
+				return false
+			}
+		case globSTAR:
+			for k := 0; ; {
+				if matchTokens(tokens[i+1:], str[k:]) {
+					return true
+				} else if k == len(str) || str[k] == '/' {
+					return false
+				}
+				_, size := utf8.DecodeRuneInString(str[k:])
+				k += size
+			}
+		case globDIRS:
+			for k := 0; ; {
+				if matchTokens(tokens[i+1:], str[k:]) {
+					return true
+				}
+				slash := strings.IndexByte(str[k:], '/')
+				if slash < 0 {
+					return false
+				}
+				k += slash + 1
+			}
+		case globREST:
+			return true
+		}
+	}
+	return len(str) == 0
+}
+
+// captureTokens is like matchTokens, but appends strings matched by wildcards
+// to captures.
+func captureTokens(tokens []tGlobToken, str string, captures []string) ([]string, bool) {
+	for i := 0; i < len(tokens); i++ {
+		token := &tokens[i]
+		switch token.kind {
+		case globLITERAL:
+			if strings.HasPrefix(str, token.literal) {
+				str = str[len(token.literal):]
+			} else {
+				return nil, false
+			}
+		case globANY, globCLASS:
+			if len(str) > 0 && str[0] != '/' {
+				r, size := utf8.DecodeRuneInString(str)
+				if token.kind == globANY || token.matchRune(r) {
+					captures = append(captures, str[:size])
+					str = str[size:]
+				} else {
+					return nil, false
+				}
+			} else {
+				return nil, false
+			}
+		case globSTAR:
+			for k := 0; ; {
+				if capturesAll, ok := captureTokens(tokens[i+1:], str[k:], append(captures, str[:k])); ok {
+					return capturesAll, true
+				} else if k == len(str) || str[k] == '/' {
+					return nil, false
+				}
+				_, size := utf8.DecodeRuneInString(str[k:])
+				k += size
+			}
+		case globDIRS:
+			for k := 0; ; {
+				if capturesAll, ok := captureTokens(tokens[i+1:], str[k:], append(captures, strings.TrimSuffix(str[:k], "/"))); ok {
+					return capturesAll, true
+				}
+				slash := strings.IndexByte(str[k:], '/')
+				if slash < 0 {
+					return nil, false
+				}
+				k += slash + 1
+			}
+		case globREST:
+			return append(captures, str), true
+		}
+	}
+	return captures, len(str) == 0
+}
+
+func (token *tGlobToken) matchRune(r rune) bool {
+	for i := 0; i < len(token.ranges); i += 2 {
+		if r >= token.ranges[i] && r <= token.ranges[i+1] {
+			return !token.negate
+		}
+	}
+	return token.negate
+}
+
+func matchAny(globs []*tGlob, relPath, name string) bool {
+	for _, glob := range globs {
+		if glob.match(relPath, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestGlobA(t *testing.T) {
+	matches := []string{
+		"*", "a.txt",
+		"*.txt", "a.txt",
+		"a*b*c", "aXbYc",
+		"report-202[34]-??.csv", "report-2023-01.csv",
+		"[!a]*", "b.txt",
+		"[]x]", "]",
+		"*.{txt,md}", "a.md",
+		"{a,b{c,d}}.go", "bd.go",
+		"\\*.txt", "*.txt",
+		"**/*.go", "main.go",
+		"**/*.go", "a/b/main.go",
+		"src/**", "src/a/b.txt",
+		"a/**/b/*.txt", "a/b/c.txt",
+		"a/**/b/*.txt", "a/x/y/b/c.txt",
+		"?.txt", "ä.txt",
+	}
+	for i := 0; i < len(matches); i += 2 {
+		glob, err := newGlob(matches[i])
+		if err != nil {
+			t.Error(err.Error())
+		} else if !glob.match(matches[i+1], path.Base(matches[i+1])) {
+			t.Error(matches[i], "doesn't match", matches[i+1])
+		}
+	}
+}
+
+func TestGlobB(t *testing.T) {
+	mismatches := []string{
+		"*.txt", "a.md",
+		"report-202[34]-??.csv", "report-2022-01.csv",
+		"report-202[34]-??.csv", "report-2023-1.csv",
+		"[!a]*", "a.txt",
+		"*.{txt,md}", "a.go",
+		"*/*.go", "a/b/main.go",
+		"a/**/b/*.txt", "a/x/c.txt",
+		"?.txt", "ab.txt",
+	}
+	for i := 0; i < len(mismatches); i += 2 {
+		glob, err := newGlob(mismatches[i])
+		if err != nil {
+			t.Error(err.Error())
+		} else if glob.match(mismatches[i+1], path.Base(mismatches[i+1])) {
+			t.Error(mismatches[i], "matches", mismatches[i+1])
+		}
+	}
+	for _, pattern := range []string{"[a-", "[]", "[z-a]", "{a,b", "a\\"} {
+		_, err := newGlob(pattern)
+		if err == nil {
+			t.Error("malformed pattern not recognized:", pattern)
+		}
+	}
+}
+
+func TestGlobC(t *testing.T) {
+	glob, _ := newGlob("**/report-[0-9]*.{csv,txt}")
+	allocs := testing.AllocsPerRun(100, func() {
+		glob.match("a/b/report-2023.csv", "report-2023.csv")
+	})
+	if allocs > 0 {
+		t.Error("matching allocates memory:", allocs)
+	}
+}
+
+func TestGlobD(t *testing.T) {
+	captures := []string{
+		"*-*.txt", "a-b-c.txt", "a|b-c",
+		"scan-??.{pdf,png}", "scan-07.png", "0|7",
+		"**/[a-z]*.go", "x/y/main.go", "x/y|m|ain",
+		"src/**", "src/a/b.txt", "a/b.txt",
+	}
+	for i := 0; i < len(captures); i += 3 {
+		glob, err := newGlob(captures[i])
+		if err != nil {
+			t.Error(err.Error())
+		} else if joined := strings.Join(glob.captures(captures[i+1], path.Base(captures[i+1])), "|"); joined != captures[i+2] {
+			t.Error(captures[i], captures[i+1], joined)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFileNames are read in every directory, if ignore files are honoured.
+var ignoreFileNames = []string{".gitignore", ".ignore", ".fbcignore"}
+
+// tIgnoreRule is a line of an ignore file (gitignore syntax).
+type tIgnoreRule struct {
+	glob    *tGlob
+	negate  bool
+	dirOnly bool
+}
+
+// tIgnoreLevel holds rules of ignore files in one directory.
+type tIgnoreLevel struct {
+	// dirPrefix is the directory path with ending separator
+	dirPrefix string
+	rules     []tIgnoreRule
+}
+
+// tIgnoreStack holds rules of the current directory and its parents.
+type tIgnoreStack struct {
+	levels []tIgnoreLevel
+}
+
+// update removes rules of directories, that don't contain path.
+func (stack *tIgnoreStack) update(path string) {
+	for len(stack.levels) > 0 && !strings.HasPrefix(path, stack.levels[len(stack.levels)-1].dirPrefix) {
+		stack.levels = stack.levels[:len(stack.levels)-1]
+	}
+}
+
+// enter reads ignore files in directory dir.
+func (stack *tIgnoreStack) enter(dir string) {
+	var level tIgnoreLevel
+	level.dirPrefix = dir
+	if !strings.HasSuffix(dir, string(filepath.Separator)) {
+		level.dirPrefix += string(filepath.Separator)
+	}
+	for _, fileName := range ignoreFileNames {
+		level.rules = appendIgnoreRules(level.rules, filepath.Join(dir, fileName))
+	}
+	if len(level.rules) > 0 {
+		stack.levels = append(stack.levels, level)
+	}
+}
+
+// isIgnored returns true, if the last matching rule is not negated. Rules in
+// deeper directories have precedence.
+func (stack *tIgnoreStack) isIgnored(path string, info os.FileInfo) bool {
+	var ignored bool
+	name := info.Name()
+	for _, level := range stack.levels {
+		relPath := filepath.ToSlash(path[len(level.dirPrefix):])
+		for i := range level.rules {
+			rule := &level.rules[i]
+			if (!rule.dirOnly || info.IsDir()) && rule.glob.match(relPath, name) {
+				ignored = !rule.negate
+			}
+		}
+	}
+	return ignored
+}
+
+// appendIgnoreRules appends rules from ignore file. Unreadable files and malformed
+// lines are skipped.
+func appendIgnoreRules(rules []tIgnoreRule, path string) []tIgnoreRule {
+	file, err := os.Open(path)
+	if err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			rule, ok := parseIgnoreRule(scanner.Text())
+			if ok {
+				rules = append(rules, rule)
+			}
+		}
+	}
+	return rules
+}
+
+func parseIgnoreRule(line string) (tIgnoreRule, bool) {
+	var rule tIgnoreRule
+	line = strings.TrimRight(line, "\r")
+	// trailing spaces are ignored, unless escaped
+	for len(line) > 0 && line[len(line)-1] == ' ' && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	if len(line) > 0 && line[0] != '#' {
+		if line[0] == '!' {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		// pattern with slash is relative to directory of ignore file
+		anchored := strings.ContainsRune(line, '/')
+		line = strings.TrimPrefix(line, "/")
+		if len(line) > 0 {
+			var err error
+			// braces are no wildcards in gitignore syntax
+			rule.glob, err = newGlob(strings.ReplaceAll(line, "{", "\\{"))
+			if err == nil {
+				rule.glob.path = anchored
+				return rule, true
+			}
+		}
+	}
+	return rule, false
+}
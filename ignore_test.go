@@ -0,0 +1,57 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"github.com/vbsw/golib/osargs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnoreFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".gitignore":              "node_modules/\n*.log\n!keep.log\n/build\n",
+		"a.txt":                   "alice",
+		"a.log":                   "alice",
+		"keep.log":                "alice",
+		"node_modules/m/x.txt":    "alice",
+		"build/b.txt":             "alice",
+		"src/build/c.txt":         "alice",
+		"src/.fbcignore":          "d.txt\n",
+		"src/d.txt":               "alice",
+		"src/sub/d.txt":           "alice",
+		"src/sub/e.txt":           "alice",
+		".git/config":             "alice",
+		"other/.ignore":           "# comment\n\n*\n",
+		"other/ignored_by_any.md": "alice",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0777)
+		os.WriteFile(path, []byte(content), 0666)
+	}
+	args := new(osargs.Arguments)
+	args.Values = []string{"count", "-r", "-g", dir, "alice"}
+	args.Parsed = make([]bool, len(args.Values))
+	params := new(tParameters)
+	err := params.initFromArgs(args)
+	if err == nil {
+		proc := new(tFileProcessorCount)
+		proc.init(params)
+		err = iterateFiles(params, proc)
+		if err == nil && proc.count != 4 {
+			// a.txt, keep.log, src/build/c.txt, src/sub/e.txt
+			t.Error(proc.count)
+		}
+	}
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
@@ -0,0 +1,201 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	journalMV     = "mv"
+	journalRENAME = "rename"
+	journalRM     = "rm"
+)
+
+// tJournal logs file operations, so they can be undone. One record per line
+// is written in JSON.
+type tJournal struct {
+	file  *os.File
+	path  string
+	mutex sync.Mutex
+}
+
+// tJournalRecord is an operation, that moved file Source to Destination.
+// rm moves files into trash, i.e. it is also a move.
+type tJournalRecord struct {
+	Operation   string `json:"op"`
+	Source      string `json:"src"`
+	Destination string `json:"dst"`
+	Time        string `json:"time"`
+	SHA256      string `json:"sha256"`
+}
+
+func openJournal(path string) (*tJournal, error) {
+	// records and trash need absolute paths
+	path, err := filepath.Abs(path)
+	var file *os.File
+	if err == nil {
+		file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	}
+	if err == nil {
+		journal := new(tJournal)
+		journal.file = file
+		journal.path = path
+		return journal, nil
+	}
+	return nil, errors.New("can't open journal: " + err.Error())
+}
+
+// trashDir returns directory for files removed in this run.
+func (journal *tJournal) trashDir(now time.Time) string {
+	return filepath.Join(journal.path+".trash", now.Format("20060102-150405.000000000"))
+}
+
+func (journal *tJournal) write(operation, source, destination, hash string) error {
+	record := tJournalRecord{operation, source, destination, time.Now().Format(time.RFC3339Nano), hash}
+	line, err := json.Marshal(&record)
+	if err == nil {
+		journal.mutex.Lock()
+		defer journal.mutex.Unlock()
+		_, err = journal.file.Write(append(line, '\n'))
+	}
+	return err
+}
+
+func (journal *tJournal) close() error {
+	return journal.file.Close()
+}
+
+// readJournal returns all records of journal file at path.
+func readJournal(path string) ([]tJournalRecord, error) {
+	var records []tJournalRecord
+	file, err := os.Open(path)
+	if err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for lineNumber := 1; scanner.Scan() && err == nil; lineNumber++ {
+			if len(scanner.Bytes()) > 0 {
+				var record tJournalRecord
+				err = json.Unmarshal(scanner.Bytes(), &record)
+				if err == nil && (len(record.Source) == 0 || len(record.Destination) == 0) {
+					err = errors.New("missing path")
+				}
+				if err == nil {
+					records = append(records, record)
+				} else {
+					err = errors.New("wrong journal record in line " + strconv.Itoa(lineNumber) + ": " + err.Error())
+				}
+			}
+		}
+		if err == nil {
+			err = scanner.Err()
+		}
+	}
+	return records, err
+}
+
+// undoJournal moves files back to their original location, last operation first.
+// Files that changed since the operation or whose original location is occupied
+// are skipped. Restored records are removed from journal, so it can be undone
+// again. Returns number of restored files.
+func undoJournal(path string, dryRun, silent bool) (int, error) {
+	var count int
+	journalRecords, err := readJournal(path)
+	if err == nil {
+		restored := make([]bool, len(journalRecords))
+		for i := len(journalRecords) - 1; i >= 0; i-- {
+			errUndo := undoRecord(&journalRecords[i], dryRun)
+			if errUndo == nil {
+				restored[i] = true
+				count++
+			} else if !silent {
+				printWarning(errUndo)
+			}
+		}
+		if !dryRun && count > 0 {
+			err = rewriteJournal(path, journalRecords, restored)
+		}
+	}
+	return count, err
+}
+
+// rewriteJournal writes records to journal file at path, except restored ones.
+// Journal is replaced at once, so that it is complete, if undo is interrupted.
+func rewriteJournal(path string, journalRecords []tJournalRecord, restored []bool) error {
+	var data []byte
+	for i := range journalRecords {
+		if !restored[i] {
+			line, err := json.Marshal(&journalRecords[i])
+			if err != nil {
+				return err
+			}
+			data = append(append(data, line...), '\n')
+		}
+	}
+	tempPath, err := writeTemp(filepath.Dir(path), bytes.NewReader(data))
+	if err == nil {
+		err = commitTemp(tempPath, path)
+	}
+	if err != nil {
+		err = errors.New("can't update journal: " + err.Error())
+	}
+	return err
+}
+
+func undoRecord(record *tJournalRecord, dryRun bool) error {
+	// for records, since destination is moved
+	info, _ := os.Stat(record.Destination)
+	hash, err := fileHash(record.Destination)
+	if err == nil {
+		if hash != record.SHA256 {
+			err = errors.New("file changed since " + record.Operation + ": " + record.Destination)
+		} else if _, errStat := os.Lstat(record.Source); errStat == nil || !os.IsNotExist(errStat) {
+			err = errors.New("target file already exists: " + record.Source)
+		} else if dryRun {
+			printOperation("rename", record.Destination, record.Source)
+		} else {
+			err = os.MkdirAll(filepath.Dir(record.Source), 0777)
+			if err == nil {
+				err = renameFile(record.Destination, record.Source)
+				if err == nil {
+					removeTrashInfo(record.Destination)
+				}
+			}
+		}
+		if err == nil && records.enabled() {
+			records.write(newFileRecord(record.Destination, "", info, nil, nil, "restore", record.Source))
+		}
+	}
+	return err
+}
+
+// fileHash returns SHA-256 of file content in hex.
+func fileHash(path string) (string, error) {
+	file, err := os.Open(path)
+	if err == nil {
+		defer file.Close()
+		hash := sha256.New()
+		_, err = io.Copy(hash, file)
+		if err == nil {
+			return hex.EncodeToString(hash.Sum(nil)), nil
+		}
+	}
+	return "", err
+}
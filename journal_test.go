@@ -0,0 +1,82 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"github.com/vbsw/golib/osargs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUndoJournal(t *testing.T) {
+	dir, inputDir, outputDir := newTestDirs(t, map[string]string{"in/sub/a.txt": "alice", "in/b.txt": "alice", "in/c.txt": "alice"})
+	journalPath := filepath.Join(dir, "fbc.journal")
+	runTestCommand(t, false, "mv", "-r", "--journal="+journalPath, inputDir+"/sub", outputDir, "alice")
+	runTestCommand(t, false, "rm", "--journal="+journalPath, inputDir+"/*.txt", "alice")
+	if _, err := os.Stat(filepath.Join(outputDir, "a.txt")); err != nil {
+		t.Error(err.Error())
+	}
+	// changed file must not be restored
+	trashDirs, _ := filepath.Glob(journalPath + ".trash/*")
+	if len(trashDirs) == 1 {
+		os.WriteFile(filepath.Join(trashDirs[0], "c.txt"), []byte("bob"), 0666)
+	} else {
+		t.Error(trashDirs)
+	}
+	count, err := undoJournal(journalPath, false, true)
+	if err != nil {
+		t.Error(err.Error())
+	} else if count != 2 {
+		t.Error(count)
+	}
+	for _, name := range []string{filepath.Join("sub", "a.txt"), "b.txt"} {
+		if _, err := os.Stat(filepath.Join(inputDir, name)); err != nil {
+			t.Error(err.Error())
+		}
+	}
+	if _, err := os.Stat(filepath.Join(inputDir, "c.txt")); err == nil {
+		t.Error("changed file restored")
+	}
+	// only record of changed file is left
+	if records, err := readJournal(journalPath); err != nil {
+		t.Error(err.Error())
+	} else if len(records) != 1 || filepath.Base(records[0].Source) != "c.txt" {
+		t.Error(records)
+	}
+	count, err = undoJournal(journalPath, false, true)
+	if err != nil {
+		t.Error(err.Error())
+	} else if count != 0 {
+		t.Error(count)
+	}
+}
+
+// runTestCommand runs command with arguments values. If summary is true,
+// printSummary is called, which completes commands, e.g. sets metadata of
+// directories.
+func runTestCommand(t *testing.T, summary bool, values ...string) {
+	args := new(osargs.Arguments)
+	args.Values = values
+	args.Parsed = make([]bool, len(args.Values))
+	params := new(tParameters)
+	err := params.initFromArgs(args)
+	if err == nil {
+		params.initOutput()
+		proc := newFileProcessor(params)
+		err = iterateFiles(params, proc)
+		if summary {
+			proc.printSummary(err)
+		} else if params.journalLog != nil {
+			params.journalLog.close()
+		}
+	}
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
@@ -0,0 +1,214 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// binaryCheckSize is the size of first block checked for NUL bytes.
+const binaryCheckSize = 32 * 1024
+
+// escape sequences of highlighted matches (like grep)
+const (
+	colorMATCH = "\x1b[01;31m"
+	colorRESET = "\x1b[m"
+)
+
+// tLinePrinter formats lines containing filter terms like grep, i.e.
+// NAME:LINE:COLUMN:TEXT and context lines as NAME-LINE-TEXT. Groups of lines
+// are separated by --, if context is printed.
+type tLinePrinter struct {
+	terms  []tTerm
+	before int
+	after  int
+	color  bool
+}
+
+// tLine is a line of content with its number (beginning at 1).
+type tLine struct {
+	number int
+	text   []byte
+}
+
+// newLinePrinter returns printer highlighting terms of filter, that are not
+// negated by NOT.
+func newLinePrinter(filter *tContentFilter, before, after int, color bool) *tLinePrinter {
+	printer := new(tLinePrinter)
+	positive := make([]bool, len(filter.terms))
+	filter.expr.markPositive(positive, false)
+	for i, term := range filter.terms {
+		if positive[i] {
+			printer.terms = append(printer.terms, term)
+		}
+	}
+	printer.before = before
+	printer.after = after
+	printer.color = color
+	return printer
+}
+
+// parseContext parses number of context lines.
+func parseContext(str string) (int, error) {
+	number, err := strconv.Atoi(str)
+	if err != nil || number < 0 {
+		return 0, errors.New("wrong number of context lines: " + str)
+	}
+	return number, nil
+}
+
+// parseColor returns true, if matches are highlighted. when is auto (or
+// empty), always or never. auto highlights only on terminals and respects
+// environment variable NO_COLOR.
+func parseColor(when string) (bool, error) {
+	switch when {
+	case "", "auto":
+		info, err := os.Stdout.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0 && len(os.Getenv("NO_COLOR")) == 0 && os.Getenv("TERM") != "dumb", nil
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	}
+	return false, errors.New("wrong color option: " + when)
+}
+
+// lines returns formatted lines of content, that contain terms. name
+// precedes every line. Content with NUL bytes in the first block or before a
+// match is binary; only a note is returned for it (like grep).
+func (printer *tLinePrinter) lines(name string, reader io.Reader) (string, error) {
+	var output strings.Builder
+	var beforeLines []tLine
+	var afterCount, lastNumber int
+	bufReader := bufio.NewReaderSize(reader, binaryCheckSize)
+	head, _ := bufReader.Peek(binaryCheckSize)
+	binary := bytes.IndexByte(head, 0) >= 0
+	for number := 1; ; number++ {
+		text, err := bufReader.ReadBytes('\n')
+		if len(text) > 0 {
+			text = bytes.TrimSuffix(text, []byte{'\n'})
+			binary = binary || bytes.IndexByte(text, 0) >= 0
+			if matches := printer.matches(text); len(matches) > 0 {
+				if binary {
+					return name + ": binary file matches\n", nil
+				}
+				for _, line := range beforeLines {
+					printer.writeContext(&output, name, line, &lastNumber)
+				}
+				beforeLines = beforeLines[:0]
+				printer.writeSeparator(&output, number, lastNumber)
+				output.WriteString(name + ":" + strconv.Itoa(number) + ":" + strconv.Itoa(matches[0][0]+1) + ":")
+				printer.writeHighlighted(&output, text, matches)
+				lastNumber, afterCount = number, printer.after
+			} else if afterCount > 0 {
+				printer.writeContext(&output, name, tLine{number, text}, &lastNumber)
+				afterCount--
+			} else if printer.before > 0 {
+				if len(beforeLines) == printer.before {
+					copy(beforeLines, beforeLines[1:])
+					beforeLines = beforeLines[:len(beforeLines)-1]
+				}
+				// ReadBytes returns a new slice, i.e. copy is not needed
+				beforeLines = append(beforeLines, tLine{number, text})
+			}
+		}
+		if err == io.EOF {
+			return output.String(), nil
+		} else if err != nil {
+			return "", err
+		}
+	}
+}
+
+func (printer *tLinePrinter) writeContext(output *strings.Builder, name string, line tLine, lastNumber *int) {
+	printer.writeSeparator(output, line.number, *lastNumber)
+	output.WriteString(name + "-" + strconv.Itoa(line.number) + "-")
+	output.Write(line.text)
+	output.WriteByte('\n')
+	*lastNumber = line.number
+}
+
+// writeSeparator writes --, if line doesn't follow the last written line and
+// context is printed.
+func (printer *tLinePrinter) writeSeparator(output *strings.Builder, number, lastNumber int) {
+	if lastNumber > 0 && number > lastNumber+1 && (printer.before > 0 || printer.after > 0) {
+		output.WriteString("--\n")
+	}
+}
+
+// writeHighlighted writes text with matches highlighted, if color is set.
+func (printer *tLinePrinter) writeHighlighted(output *strings.Builder, text []byte, matches [][2]int) {
+	if printer.color {
+		var last int
+		for _, match := range matches {
+			output.Write(text[last:match[0]])
+			output.WriteString(colorMATCH)
+			output.Write(text[match[0]:match[1]])
+			output.WriteString(colorRESET)
+			last = match[1]
+		}
+		output.Write(text[last:])
+	} else {
+		output.Write(text)
+	}
+	output.WriteByte('\n')
+}
+
+// matches returns sorted, non-overlapping positions of terms in text.
+func (printer *tLinePrinter) matches(text []byte) [][2]int {
+	var matches [][2]int
+	for i := range printer.terms {
+		matches = append(matches, printer.terms[i].indexAll(text)...)
+	}
+	if len(matches) > 1 {
+		sort.Slice(matches, func(i, k int) bool {
+			return matches[i][0] < matches[k][0]
+		})
+		merged := matches[:1]
+		for _, match := range matches[1:] {
+			last := &merged[len(merged)-1]
+			if match[0] <= last[1] {
+				last[1] = maxInt(last[1], match[1])
+			} else {
+				merged = append(merged, match)
+			}
+		}
+		matches = merged
+	}
+	return matches
+}
+
+// indexAll returns positions of all non-empty matches in data.
+func (term *tTerm) indexAll(data []byte) [][2]int {
+	var matches [][2]int
+	if term.regex == nil {
+		for offset := 0; offset < len(data); {
+			index := bytes.Index(data[offset:], term.literal)
+			if index < 0 {
+				break
+			}
+			begin := offset + index
+			matches = append(matches, [2]int{begin, begin + len(term.literal)})
+			offset = begin + len(term.literal)
+		}
+	} else {
+		for _, match := range term.regex.FindAllIndex(data, -1) {
+			if match[1] > match[0] {
+				matches = append(matches, [2]int{match[0], match[1]})
+			}
+		}
+	}
+	return matches
+}
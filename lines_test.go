@@ -0,0 +1,34 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	filter := &tContentFilter{terms: []tTerm{newLiteralTerm("alice"), newLiteralTerm("bob")}}
+	filter.expr, _, _ = parseExpr([]string{"alice AND NOT bob"})
+	content := "1\nalice\n3\n4\n5\n6\nbob alice alice\n8"
+	printer := newLinePrinter(filter, 1, 1, false)
+	expected := "a.txt-1-1\na.txt:2:1:alice\na.txt-3-3\n--\na.txt-6-6\na.txt:7:5:bob alice alice\na.txt-8-8\n"
+	if lines, err := printer.lines("a.txt", strings.NewReader(content)); err != nil {
+		t.Error(err.Error())
+	} else if lines != expected {
+		t.Error(lines)
+	}
+	printer = newLinePrinter(filter, 0, 0, true)
+	expected = "a.txt:2:1:" + colorMATCH + "alice" + colorRESET + "\na.txt:7:5:bob " + colorMATCH + "alice" + colorRESET + " " + colorMATCH + "alice" + colorRESET + "\n"
+	if lines, _ := printer.lines("a.txt", strings.NewReader(content)); lines != expected {
+		t.Error(lines)
+	}
+	if lines, _ := printer.lines("a.bin", strings.NewReader("alice\n\x00")); lines != "a.bin: binary file matches\n" {
+		t.Error(lines)
+	}
+}
@@ -0,0 +1,202 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"errors"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	cmpEQUAL = iota
+	cmpGREATER
+	cmpLESS
+)
+
+const (
+	permEXACT = iota
+	permALL
+	permANY
+)
+
+// tMetaFilter matches file size, modification time and permissions.
+type tMetaFilter struct {
+	sizes      []tSizeCondition
+	newer      time.Time
+	older      time.Time
+	perm       os.FileMode
+	permMode   int
+	permSet    bool
+	executable bool
+}
+
+type tSizeCondition struct {
+	size int64
+	cmp  int
+}
+
+func newMetaFilter(params *tParameters) (*tMetaFilter, error) {
+	var err error
+	filter := new(tMetaFilter)
+	now := time.Now()
+	for i := 0; i < len(params.size.Values) && err == nil; i++ {
+		var condition tSizeCondition
+		condition, err = parseSizeCondition(params.size.Values[i])
+		filter.sizes = append(filter.sizes, condition)
+	}
+	if err == nil && params.newer.Available() {
+		filter.newer, err = parseTime(params.newer.Values[0], now)
+	}
+	if err == nil && params.older.Available() {
+		filter.older, err = parseTime(params.older.Values[0], now)
+	}
+	if err == nil && params.perm.Available() {
+		filter.perm, filter.permMode, err = parsePerm(params.perm.Values[0])
+		filter.permSet = true
+	}
+	filter.executable = params.executable.Available()
+	return filter, err
+}
+
+// parseSizeCondition parses sizes like +10M (greater), -1k (less) or 512 (equal).
+// Units are k, M, G and T (powers of 1024).
+func parseSizeCondition(str string) (tSizeCondition, error) {
+	var condition tSizeCondition
+	value := str
+	if strings.HasPrefix(value, "+") {
+		condition.cmp = cmpGREATER
+		value = value[1:]
+	} else if strings.HasPrefix(value, "-") {
+		condition.cmp = cmpLESS
+		value = value[1:]
+	}
+	factor := int64(1)
+	if len(value) > 0 {
+		switch value[len(value)-1] {
+		case 'k', 'K':
+			factor = 1024
+		case 'm', 'M':
+			factor = 1024 * 1024
+		case 'g', 'G':
+			factor = 1024 * 1024 * 1024
+		case 't', 'T':
+			factor = 1024 * 1024 * 1024 * 1024
+		}
+		if factor > 1 {
+			value = value[:len(value)-1]
+		}
+	}
+	size, err := strconv.ParseInt(value, 10, 64)
+	// size * factor must not overflow
+	if err == nil && size >= 0 && size <= math.MaxInt64/factor {
+		condition.size = size * factor
+		return condition, nil
+	}
+	return condition, errors.New("wrong size: " + str)
+}
+
+// parseTime parses dates like 2026-01-01, 2026-01-01T12:00:00 (local time), RFC 3339
+// or durations like 30d, 12h, 2w, 15m, 10s (before now).
+func parseTime(str string, now time.Time) (time.Time, error) {
+	layouts := []string{"2006-01-02", "2006-01-02T15:04:05", "2006-01-02 15:04:05"}
+	for _, layout := range layouts {
+		t, err := time.ParseInLocation(layout, str, time.Local)
+		if err == nil {
+			return t, nil
+		}
+	}
+	t, err := time.Parse(time.RFC3339, str)
+	if err == nil {
+		return t, nil
+	}
+	if len(str) > 1 {
+		var unit time.Duration
+		switch str[len(str)-1] {
+		case 's':
+			unit = time.Second
+		case 'm':
+			unit = time.Minute
+		case 'h':
+			unit = time.Hour
+		case 'd':
+			unit = time.Hour * 24
+		case 'w':
+			unit = time.Hour * 24 * 7
+		}
+		count, err := strconv.ParseInt(str[:len(str)-1], 10, 64)
+		if unit > 0 && err == nil && count >= 0 {
+			return now.Add(-time.Duration(count) * unit), nil
+		}
+	}
+	return t, errors.New("wrong time: " + str)
+}
+
+// parsePerm parses octal permissions like 0644 (exact), -0600 (all bits set)
+// or /0111 (any bit set).
+func parsePerm(str string) (os.FileMode, int, error) {
+	mode := permEXACT
+	value := str
+	if strings.HasPrefix(value, "-") {
+		mode = permALL
+		value = value[1:]
+	} else if strings.HasPrefix(value, "/") {
+		mode = permANY
+		value = value[1:]
+	}
+	perm, err := strconv.ParseUint(value, 8, 32)
+	if err == nil && perm <= 0777 {
+		return os.FileMode(perm), mode, nil
+	}
+	return 0, mode, errors.New("wrong permissions: " + str)
+}
+
+func (filter *tMetaFilter) isMatch(info os.FileInfo) bool {
+	for _, condition := range filter.sizes {
+		if !condition.isMatch(info.Size()) {
+			return false
+		}
+	}
+	if !filter.newer.IsZero() && !info.ModTime().After(filter.newer) {
+		return false
+	}
+	if !filter.older.IsZero() && !info.ModTime().Before(filter.older) {
+		return false
+	}
+	perm := info.Mode().Perm()
+	if filter.permSet {
+		switch filter.permMode {
+		case permEXACT:
+			if perm != filter.perm {
+				return false
+			}
+		case permALL:
+			if perm&filter.perm != filter.perm {
+				return false
+			}
+		case permANY:
+			if perm&filter.perm == 0 && filter.perm != 0 {
+				return false
+			}
+		}
+	}
+	return !filter.executable || perm&0111 != 0
+}
+
+func (condition *tSizeCondition) isMatch(size int64) bool {
+	switch condition.cmp {
+	case cmpGREATER:
+		return size > condition.size
+	case cmpLESS:
+		return size < condition.size
+	}
+	return size == condition.size
+}
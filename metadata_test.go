@@ -0,0 +1,62 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSizeCondition(t *testing.T) {
+	condition, err := parseSizeCondition("+10M")
+	if err != nil {
+		t.Error(err.Error())
+	} else if condition.cmp != cmpGREATER || condition.size != 10*1024*1024 {
+		t.Error(condition)
+	} else if condition.isMatch(10*1024*1024) || !condition.isMatch(10*1024*1024+1) {
+		t.Error("size +10M not recognized")
+	}
+	condition, err = parseSizeCondition("-1k")
+	if err != nil {
+		t.Error(err.Error())
+	} else if !condition.isMatch(1023) || condition.isMatch(1024) {
+		t.Error("size -1k not recognized")
+	}
+	for _, str := range []string{"", "+", "1x", "--1", "k", "9223372036854775807k", "+8388608T"} {
+		_, err = parseSizeCondition(str)
+		if err == nil {
+			t.Error("malformed size not recognized:", str)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local)
+	tm, err := parseTime("30d", now)
+	if err != nil {
+		t.Error(err.Error())
+	} else if !tm.Equal(now.Add(-30 * 24 * time.Hour)) {
+		t.Error(tm)
+	}
+	tm, err = parseTime("2026-01-01", now)
+	if err != nil {
+		t.Error(err.Error())
+	} else if !tm.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local)) {
+		t.Error(tm)
+	}
+	_, err = parseTime("yesterday", now)
+	if err == nil {
+		t.Error("malformed time not recognized")
+	}
+	perm, mode, err := parsePerm("/0111")
+	if err != nil {
+		t.Error(err.Error())
+	} else if perm != 0111 || mode != permANY {
+		t.Error(perm, mode)
+	}
+}
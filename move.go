@@ -0,0 +1,76 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+)
+
+// errNotSameDevice is ERROR_NOT_SAME_DEVICE on Windows.
+const errNotSameDevice = syscall.Errno(17)
+
+// renameFile renames file. If source and destination are on different devices,
+// the file is copied and source is removed.
+func renameFile(source, destination string) error {
+	err := os.Rename(source, destination)
+	if err != nil && isCrossDevice(err) {
+		err = moveAcrossDevices(source, destination)
+	}
+	return err
+}
+
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV) || runtime.GOOS == "windows" && errors.Is(err, errNotSameDevice)
+}
+
+// moveAcrossDevices copies source to a temporary file in directory of destination,
+// syncs and verifies it and renames it to destination. Source is removed only
+// after the copy is complete.
+func moveAcrossDevices(source, destination string) error {
+	info, err := os.Lstat(source)
+	if err == nil && !info.Mode().IsRegular() {
+		err = errors.New("can't move across devices (not a regular file): " + source)
+	}
+	if err == nil {
+		var tempPath string
+		tempPath, err = copyToTemp(source, filepath.Dir(destination))
+		if err == nil {
+			err = applyMetadata(tempPath, source, info, preserveMODE|preserveTIMESTAMPS|preserveOWNERSHIP|preserveXATTR)
+			if err == nil {
+				var equal bool
+				equal, err = isFileEqual(source, tempPath)
+				if err == nil && !equal {
+					err = errors.New("copy differs from source: " + source)
+				}
+			}
+			if err == nil {
+				err = commitTemp(tempPath, destination)
+				if err == nil {
+					err = os.Remove(source)
+				}
+			} else {
+				os.Remove(tempPath)
+			}
+		}
+	}
+	return err
+}
+
+// copyToTemp copies file to a new temporary file in directory dir and syncs it.
+func copyToTemp(source, dir string) (string, error) {
+	sourceFile, err := os.Open(source)
+	if err == nil {
+		defer sourceFile.Close()
+		return writeTemp(dir, sourceFile)
+	}
+	return "", err
+}
@@ -0,0 +1,38 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMoveAcrossDevices(t *testing.T) {
+	dir := t.TempDir()
+	source, destination := filepath.Join(dir, "a.txt"), filepath.Join(dir, "sub", "a.txt")
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)
+	os.MkdirAll(filepath.Join(dir, "sub"), 0777)
+	os.WriteFile(source, []byte("alice"), 0640)
+	os.Chtimes(source, modTime, modTime)
+	err := moveAcrossDevices(source, destination)
+	if err != nil {
+		t.Error(err.Error())
+	} else if _, err = os.Stat(source); err == nil {
+		t.Error("source not removed")
+	} else if info, err := os.Stat(destination); err != nil {
+		t.Error(err.Error())
+	} else if info.Mode().Perm() != 0640 || !info.ModTime().Equal(modTime) {
+		t.Error(info.Mode(), info.ModTime())
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, "sub"))
+	if len(entries) != 1 {
+		t.Error("temporary file not removed")
+	}
+}
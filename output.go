@@ -0,0 +1,131 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	formatTEXT = iota
+	formatJSONL
+	formatCSV
+)
+
+// types of records
+const (
+	recordFILE    = "file"
+	recordTERM    = "term"
+	recordWARNING = "warning"
+	recordERROR   = "error"
+	recordSUMMARY = "summary"
+)
+
+// print0 is true, if file names are terminated with NUL. Warnings and errors
+// are printed to stderr then.
+var print0 bool
+
+// records writes output in JSON Lines or CSV format instead of text, if
+// enabled.
+var records tRecordWriter
+
+// tRecordWriter writes records in JSON Lines or CSV format.
+type tRecordWriter struct {
+	format int
+	// dryRun is true, if actions of records are only planned
+	dryRun bool
+	// csvHeader is true, if CSV header has been written
+	csvHeader bool
+	mutex     sync.Mutex
+}
+
+// tRecord is a line of machine-readable output. All fields are always
+// written, so that the schema is stable. Terms are found filter terms of
+// files. Action is the operation performed on file, e.g. copy, skip or
+// remove. DryRun is true, if action is only planned.
+type tRecord struct {
+	Type    string   `json:"type"`
+	Path    string   `json:"path"`
+	RelPath string   `json:"rel_path"`
+	Size    int64    `json:"size"`
+	MTime   string   `json:"mtime"`
+	Terms   []string `json:"terms"`
+	Action  string   `json:"action"`
+	DryRun  bool     `json:"dry_run"`
+	Target  string   `json:"target"`
+	Error   string   `json:"error"`
+	Count   int      `json:"count"`
+	Message string   `json:"message"`
+}
+
+// recordColumns is the CSV header. Terms are separated by ; in CSV.
+var recordColumns = []string{"type", "path", "rel_path", "size", "mtime", "terms", "action", "dry_run", "target", "error", "count", "message"}
+
+func parseFormat(str string) (int, error) {
+	switch str {
+	case "text":
+		return formatTEXT, nil
+	case "jsonl":
+		return formatJSONL, nil
+	case "csv":
+		return formatCSV, nil
+	}
+	return formatTEXT, errors.New("wrong format: " + str)
+}
+
+// enabled returns true, if output is machine-readable.
+func (writer *tRecordWriter) enabled() bool {
+	return writer.format != formatTEXT
+}
+
+// write writes record to stdout. Records of threads don't mix.
+func (writer *tRecordWriter) write(record *tRecord) {
+	if record.Terms == nil {
+		record.Terms = []string{}
+	}
+	record.DryRun = writer.dryRun
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	if writer.format == formatJSONL {
+		line, err := json.Marshal(record)
+		if err == nil {
+			os.Stdout.Write(append(line, '\n'))
+		}
+	} else {
+		csvWriter := csv.NewWriter(os.Stdout)
+		if !writer.csvHeader {
+			csvWriter.Write(recordColumns)
+			writer.csvHeader = true
+		}
+		csvWriter.Write([]string{record.Type, record.Path, record.RelPath, strconv.FormatInt(record.Size, 10), record.MTime, strings.Join(record.Terms, ";"), record.Action, strconv.FormatBool(record.DryRun), record.Target, record.Error, strconv.Itoa(record.Count), record.Message})
+		csvWriter.Flush()
+	}
+}
+
+// newFileRecord returns record of file at path. found are the found filter
+// terms with names.
+func newFileRecord(path, relPath string, info os.FileInfo, names []string, found []bool, action, target string) *tRecord {
+	record := &tRecord{Type: recordFILE, Path: path, RelPath: relPath, Action: action, Target: target}
+	if info != nil {
+		record.Size = info.Size()
+		record.MTime = info.ModTime().Format(time.RFC3339)
+	}
+	for i, termFound := range found {
+		if termFound {
+			record.Terms = append(record.Terms, names[i])
+		}
+	}
+	return record
+}
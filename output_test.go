@@ -0,0 +1,83 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindTerms(t *testing.T) {
+	filter := &tContentFilter{terms: []tTerm{newLiteralTerm("alice"), newLiteralTerm("bob")}}
+	filter.expr = newExprAll(len(filter.terms), true)
+	filter.overlap = filter.maxWidth() - 1
+	content := "alice" + strings.Repeat(" ", 64) + "bob"
+	_, found, err := filter.findTerms(strings.NewReader(content), make([]byte, 32))
+	if err != nil {
+		t.Error(err.Error())
+	} else if !found[0] || found[1] {
+		// OR is decided by first term
+		t.Error(found)
+	}
+	filter.allTerms = true
+	match, found, err := filter.findTerms(strings.NewReader(content), make([]byte, 32))
+	if err != nil {
+		t.Error(err.Error())
+	} else if !match || !found[0] || !found[1] {
+		t.Error(found)
+	}
+}
+
+func TestFileRecord(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	os.WriteFile(path, []byte("alice"), 0666)
+	info, _ := os.Stat(path)
+	record := newFileRecord(path, "a.txt", info, []string{"alice", "bob"}, []bool{true, false}, "copy", "")
+	data, err := json.Marshal(record)
+	if err != nil {
+		t.Error(err.Error())
+	} else if prefix := `{"type":"file","path":"` + path + `","rel_path":"a.txt","size":5,"mtime":"`; !strings.HasPrefix(string(data), prefix) {
+		t.Error(string(data))
+	} else if suffix := `"terms":["alice"],"action":"copy","dry_run":false,"target":"","error":"","count":0,"message":""}`; !strings.HasSuffix(string(data), suffix) {
+		t.Error(string(data))
+	}
+	if format, err := parseFormat("csv"); err != nil || format != formatCSV {
+		t.Error(format)
+	} else if _, err := parseFormat("xml"); err == nil {
+		t.Error("xml accepted")
+	}
+}
+
+func TestDryRunRecord(t *testing.T) {
+	dir, inputDir, outputDir := newTestDirs(t, map[string]string{"in/a.txt": "alice"})
+	stdout, err := os.Create(filepath.Join(dir, "stdout.jsonl"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	os.Stdout, stdout = stdout, os.Stdout
+	defer func() {
+		os.Stdout, stdout = stdout, os.Stdout
+		stdout.Close()
+		records = tRecordWriter{}
+	}()
+	runTestCommand(t, false, "cp", "-n", "--format=jsonl", inputDir, outputDir, "alice")
+	runTestCommand(t, false, "cp", "--format=jsonl", inputDir, outputDir, "alice")
+	data, _ := os.ReadFile(filepath.Join(dir, "stdout.jsonl"))
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Error(lines)
+	} else if !strings.Contains(lines[0], `"action":"copy","dry_run":true`) {
+		t.Error(lines[0])
+	} else if !strings.Contains(lines[1], `"action":"copy","dry_run":false`) {
+		t.Error(lines[1])
+	}
+}
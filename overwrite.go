@@ -0,0 +1,199 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	overwriteNEVER = iota
+	overwriteALWAYS
+	overwriteNEWER
+	overwriteLARGER
+	overwriteDIFFERENT
+)
+
+// actions of cp and mv on target file
+const (
+	actionCOPY = iota
+	actionSKIP
+	actionOVERWRITE
+	actionRENAME
+	// actionCOMPARE overwrites, if content differs
+	actionCOMPARE
+)
+
+// states of target file
+const (
+	targetFREE = iota
+	// targetPLANNED is target of another file in this run
+	targetPLANNED
+	targetEXISTS
+)
+
+// tTransferStats counts actions of cp and mv.
+type tTransferStats struct {
+	copied      int
+	skipped     int
+	overwritten int
+	renamed     int
+}
+
+// tCompareWriter compares written data with content of reader.
+type tCompareWriter struct {
+	reader io.Reader
+	buffer []byte
+	equal  bool
+}
+
+func parseOverwritePolicy(str string) (int, error) {
+	switch str {
+	case "never":
+		return overwriteNEVER, nil
+	case "always":
+		return overwriteALWAYS, nil
+	case "newer":
+		return overwriteNEWER, nil
+	case "larger":
+		return overwriteLARGER, nil
+	case "different":
+		return overwriteDIFFERENT, nil
+	}
+	return overwriteNEVER, errors.New("wrong overwrite policy: " + str)
+}
+
+// overwriteAction returns action for existing target according to policy.
+// info is the source file, targetInfo the existing target.
+func overwriteAction(policy int, info, targetInfo os.FileInfo) int {
+	switch policy {
+	case overwriteALWAYS:
+		return actionOVERWRITE
+	case overwriteNEWER:
+		if info.ModTime().After(targetInfo.ModTime()) {
+			return actionOVERWRITE
+		}
+	case overwriteLARGER:
+		if info.Size() > targetInfo.Size() {
+			return actionOVERWRITE
+		}
+	case overwriteDIFFERENT:
+		if info.Size() != targetInfo.Size() {
+			return actionOVERWRITE
+		}
+		return actionCOMPARE
+	}
+	return actionSKIP
+}
+
+// numberedPath returns path with number inserted before extension, e.g. "name (1).txt".
+func numberedPath(path string, number int) string {
+	dir, name := filepath.Split(path)
+	ext := filepath.Ext(name)
+	if ext == name {
+		ext = ""
+	}
+	return dir + name[:len(name)-len(ext)] + " (" + strconv.Itoa(number) + ")" + ext
+}
+
+func (stats *tTransferStats) add(action int) {
+	switch action {
+	case actionCOPY:
+		stats.copied++
+	case actionSKIP:
+		stats.skipped++
+	case actionOVERWRITE:
+		stats.overwritten++
+	case actionRENAME:
+		stats.renamed++
+	}
+}
+
+// actionName returns name of action in records. move is true for mv.
+// Targets with unique names are renamed.
+func actionName(action int, move bool) string {
+	switch action {
+	case actionSKIP:
+		return "skip"
+	case actionOVERWRITE:
+		return "overwrite"
+	case actionRENAME:
+		return "rename"
+	}
+	if move {
+		return "move"
+	}
+	return "copy"
+}
+
+// summary returns counts of actions. verb is "copied" or "moved".
+func (stats *tTransferStats) summary(verb string) string {
+	summary := strconv.Itoa(stats.copied) + " " + verb
+	summary += ", " + strconv.Itoa(stats.skipped) + " skipped"
+	summary += ", " + strconv.Itoa(stats.overwritten) + " overwritten"
+	summary += ", " + strconv.Itoa(stats.renamed) + " renamed"
+	return summary
+}
+
+func newCompareWriter(reader io.Reader) *tCompareWriter {
+	writer := new(tCompareWriter)
+	writer.reader = reader
+	writer.equal = true
+	return writer
+}
+
+func (writer *tCompareWriter) Write(data []byte) (int, error) {
+	if writer.equal {
+		if len(writer.buffer) < len(data) {
+			writer.buffer = make([]byte, len(data))
+		}
+		buffer := writer.buffer[:len(data)]
+		_, err := io.ReadFull(writer.reader, buffer)
+		writer.equal = err == nil && bytes.Equal(buffer, data)
+	}
+	return len(data), nil
+}
+
+// isEqual returns true, if all content of reader has been written.
+func (writer *tCompareWriter) isEqual() bool {
+	if writer.equal {
+		var buffer [1]byte
+		n, _ := io.ReadFull(writer.reader, buffer[:])
+		return n == 0
+	}
+	return false
+}
+
+// isReaderEqual returns true, if reader has the same content as file at path.
+func isReaderEqual(reader io.Reader, path string) (bool, error) {
+	file, err := os.Open(path)
+	if err == nil {
+		defer file.Close()
+		writer := newCompareWriter(file)
+		_, err = io.Copy(writer, reader)
+		if err == nil {
+			return writer.isEqual(), nil
+		}
+	}
+	return false, err
+}
+
+// isFileEqual returns true, if files have the same content.
+func isFileEqual(pathA, pathB string) (bool, error) {
+	file, err := os.Open(pathA)
+	if err == nil {
+		defer file.Close()
+		return isReaderEqual(file, pathB)
+	}
+	return false, err
+}
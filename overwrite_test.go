@@ -0,0 +1,43 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOverwrite(t *testing.T) {
+	_, inputDir, outputDir := newTestDirs(t, map[string]string{"in/a.txt": "alice", "in/b.txt": "alice 2", "out/a.txt": "alice", "out/b.txt": "alice 1"})
+	runTestCommand(t, false, "cp", "--overwrite=different", inputDir, outputDir, "alice")
+	content, err := os.ReadFile(filepath.Join(outputDir, "b.txt"))
+	if err != nil || string(content) != "alice 2" {
+		t.Error("different file not overwritten")
+	}
+	runTestCommand(t, false, "cp", "--rename", inputDir, outputDir, "alice")
+	for _, name := range []string{"a (1).txt", "b (1).txt"} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err != nil {
+			t.Error(err.Error())
+		}
+	}
+	entries, _ := os.ReadDir(outputDir)
+	if len(entries) != 4 {
+		t.Error("temporary files not removed")
+	}
+}
+
+func TestNumberedPath(t *testing.T) {
+	paths := []string{"a.txt", "a (2).txt", filepath.Join("d", ".profile"), filepath.Join("d", ".profile (2)"), "a.tar.gz", "a.tar (2).gz"}
+	for i := 0; i < len(paths); i += 2 {
+		numberedPath := numberedPath(paths[i], 2)
+		if numberedPath != paths[i+1] {
+			t.Error(numberedPath)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+const (
+	preserveMODE = 1 << iota
+	preserveTIMESTAMPS
+	preserveOWNERSHIP
+	preserveXATTR
+)
+
+// preserveDEFAULT is used, if no attributes are listed (like cp -p).
+const preserveDEFAULT = preserveMODE | preserveTIMESTAMPS | preserveOWNERSHIP
+
+// parsePreserve parses comma separated list of attributes: mode, timestamps,
+// ownership, xattr or all. Empty list is the default.
+func parsePreserve(str string) (int, error) {
+	var preserve int
+	if len(str) == 0 {
+		return preserveDEFAULT, nil
+	}
+	for _, attribute := range strings.Split(str, ",") {
+		switch strings.TrimSpace(attribute) {
+		case "mode":
+			preserve |= preserveMODE
+		case "timestamps":
+			preserve |= preserveTIMESTAMPS
+		case "ownership":
+			preserve |= preserveOWNERSHIP
+		case "xattr":
+			preserve |= preserveXATTR
+		case "all":
+			preserve |= preserveMODE | preserveTIMESTAMPS | preserveOWNERSHIP | preserveXATTR
+		default:
+			return 0, errors.New("wrong preserve attribute: " + attribute)
+		}
+	}
+	return preserve, nil
+}
+
+// applyMetadata sets attributes of file info to file at path. source is the
+// path of file info; extended attributes are copied only from files, not from
+// archive members. Ownership is ignored, if not permitted.
+func applyMetadata(path, source string, info os.FileInfo, preserve int) error {
+	var err error
+	if preserve&preserveOWNERSHIP != 0 {
+		err = setOwner(path, info)
+		if os.IsPermission(err) {
+			err = nil
+		}
+	}
+	if err == nil && preserve&preserveMODE != 0 {
+		err = os.Chmod(path, info.Mode()&(os.ModePerm|os.ModeSetuid|os.ModeSetgid|os.ModeSticky))
+	}
+	if err == nil && preserve&preserveXATTR != 0 {
+		err = copyXattrs(source, path, info)
+	}
+	if err == nil && preserve&preserveTIMESTAMPS != 0 {
+		err = os.Chtimes(path, accessTime(info), info.ModTime())
+	}
+	return err
+}
@@ -0,0 +1,81 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"syscall"
+	"time"
+)
+
+// accessTime returns access time of file info, or modification time, if not available.
+func accessTime(info os.FileInfo) time.Time {
+	switch sys := info.Sys().(type) {
+	case *syscall.Stat_t:
+		return time.Unix(int64(sys.Atim.Sec), int64(sys.Atim.Nsec))
+	case *tar.Header:
+		if !sys.AccessTime.IsZero() {
+			return sys.AccessTime
+		}
+	}
+	return info.ModTime()
+}
+
+// copyXattrs copies extended attributes from file source to file path. Attributes,
+// that are not permitted or not supported by the target file system, are skipped.
+func copyXattrs(source, path string, info os.FileInfo) error {
+	if _, ok := info.Sys().(*syscall.Stat_t); ok {
+		names, err := listXattrs(source)
+		for i := 0; i < len(names) && err == nil; i++ {
+			var value []byte
+			value, err = getXattr(source, names[i])
+			if err == nil {
+				err = syscall.Setxattr(path, names[i], value, 0)
+				if err == syscall.EPERM || err == syscall.ENOTSUP {
+					err = nil
+				}
+			}
+		}
+		if err == syscall.ENOTSUP {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func listXattrs(path string) ([]string, error) {
+	var names []string
+	size, err := syscall.Listxattr(path, nil)
+	if err == nil && size > 0 {
+		buffer := make([]byte, size)
+		size, err = syscall.Listxattr(path, buffer)
+		if err == nil {
+			for _, name := range bytes.Split(buffer[:size], []byte{0}) {
+				if len(name) > 0 {
+					names = append(names, string(name))
+				}
+			}
+		}
+	}
+	return names, err
+}
+
+func getXattr(path, name string) ([]byte, error) {
+	size, err := syscall.Getxattr(path, name, nil)
+	if err == nil {
+		value := make([]byte, size)
+		size, err = syscall.Getxattr(path, name, value)
+		if err == nil {
+			return value[:size], nil
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,30 @@
+//go:build !linux
+// +build !linux
+
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"archive/tar"
+	"os"
+	"time"
+)
+
+// accessTime returns access time of tar members, or modification time otherwise.
+func accessTime(info os.FileInfo) time.Time {
+	if header, ok := info.Sys().(*tar.Header); ok && !header.AccessTime.IsZero() {
+		return header.AccessTime
+	}
+	return info.ModTime()
+}
+
+// copyXattrs does nothing, since extended attributes are supported on Linux only.
+func copyXattrs(source, path string, info os.FileInfo) error {
+	return nil
+}
@@ -0,0 +1,52 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPreserve(t *testing.T) {
+	_, inputDir, outputDir := newTestDirs(t, map[string]string{"in/sub/a.txt": "alice"})
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)
+	os.Chmod(filepath.Join(inputDir, "sub", "a.txt"), 0640)
+	os.Chtimes(filepath.Join(inputDir, "sub", "a.txt"), modTime, modTime)
+	os.Chtimes(filepath.Join(inputDir, "sub"), modTime, modTime)
+	runTestCommand(t, true, "cp", "-r", "-p", inputDir, outputDir, "alice")
+	info, err := os.Stat(filepath.Join(outputDir, "sub", "a.txt"))
+	if err != nil {
+		t.Error(err.Error())
+	} else if info.Mode().Perm() != 0640 || !info.ModTime().Equal(modTime) {
+		t.Error(info.Mode(), info.ModTime())
+	}
+	info, err = os.Stat(filepath.Join(outputDir, "sub"))
+	if err != nil {
+		t.Error(err.Error())
+	} else if !info.ModTime().Equal(modTime) {
+		t.Error(info.ModTime())
+	}
+}
+
+func TestParsePreserve(t *testing.T) {
+	preserve, err := parsePreserve("mode,xattr")
+	if err != nil || preserve != preserveMODE|preserveXATTR {
+		t.Error(preserve, err)
+	}
+	// -p without value
+	preserve, err = parsePreserve("")
+	if err != nil || preserve != preserveDEFAULT {
+		t.Error(preserve, err)
+	}
+	_, err = parsePreserve("mode,color")
+	if err == nil {
+		t.Error("wrong attribute not recognized")
+	}
+}
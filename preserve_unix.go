@@ -0,0 +1,29 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"archive/tar"
+	"os"
+	"syscall"
+)
+
+// setOwner sets user and group of file info to file at path. Tar members
+// have them in header, zip members have none.
+func setOwner(path string, info os.FileInfo) error {
+	switch sys := info.Sys().(type) {
+	case *syscall.Stat_t:
+		return os.Lchown(path, int(sys.Uid), int(sys.Gid))
+	case *tar.Header:
+		return os.Lchown(path, sys.Uid, sys.Gid)
+	}
+	return nil
+}
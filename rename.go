@@ -0,0 +1,172 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// renameFields are fields of --to, additionally to counter {n}, wildcards {1},
+// {2}, ... and capture groups {c1}, {c2}, ...
+var renameFields = []string{"name", "base", "ext", "year", "month", "day", "date"}
+
+// tRename is a planned rename of file at path to target.
+type tRename struct {
+	path   string
+	info   os.FileInfo
+	values map[string]string
+	target string
+	// found are the found filter terms
+	found []bool
+}
+
+// newRenameTemplate parses template of new file names. wildcards is the
+// number of wildcards in file name filter and groups the number of capture
+// groups in content filter.
+func newRenameTemplate(str string, wildcards, groups int) (*tTemplate, error) {
+	return newTemplate(str, func(field string) bool {
+		if containsString(renameFields, field) || field == "n" {
+			return true
+		} else if strings.HasPrefix(field, "n:") {
+			return isDigits(field[2:])
+		} else if strings.HasPrefix(field, "c") && isDigits(field[1:]) {
+			number, _ := strconv.Atoi(field[1:])
+			return number >= 1 && number <= groups
+		} else if isDigits(field) {
+			number, _ := strconv.Atoi(field)
+			return number >= 1 && number <= wildcards
+		}
+		return false
+	})
+}
+
+// renameValues returns values of rename fields except counter.
+func renameValues(info os.FileInfo, captures, groups []string) map[string]string {
+	values := fileFields(info, "")
+	values["date"] = info.ModTime().Format("2006-01-02")
+	for i, capture := range captures {
+		values[strconv.Itoa(i+1)] = capture
+	}
+	for i, group := range groups {
+		values["c"+strconv.Itoa(i+1)] = group
+	}
+	return values
+}
+
+// planRenames sorts renames by path, numbers them and sets their targets.
+// Returns renames in the order they can be executed without overwriting files,
+// i.e. a file is renamed after the file occupying its target. Unchanged files
+// are omitted. Collisions and cycles are returned as errors.
+func planRenames(renames []tRename, template *tTemplate) ([]*tRename, []error) {
+	var errs []error
+	sort.Slice(renames, func(i, k int) bool {
+		return renames[i].path < renames[k].path
+	})
+	sources := make(map[string]*tRename, len(renames))
+	for i := range renames {
+		sources[renames[i].path] = &renames[i]
+	}
+	targets := make(map[string]*tRename, len(renames))
+	for i := range renames {
+		rename := &renames[i]
+		for _, part := range template.parts {
+			if part.field == "n" || strings.HasPrefix(part.field, "n:") {
+				rename.values[part.field] = counterValue(part.field, i+1)
+			}
+		}
+		name := template.expand(rename.values)
+		if len(name) == 0 || name == "." || name == ".." || strings.ContainsAny(name, "/"+string(filepath.Separator)) {
+			errs = append(errs, errors.New("wrong file name \""+name+"\" for "+rename.path))
+		} else {
+			rename.target = filepath.Join(filepath.Dir(rename.path), name)
+			if rename.target == rename.path {
+				continue
+			} else if other := targets[rename.target]; other != nil {
+				errs = append(errs, errors.New("files have the same target: "+other.path+", "+rename.path+" -> "+rename.target))
+			} else if sources[rename.target] == nil && !isSameFileOrFree(rename.path, rename.target) {
+				errs = append(errs, errors.New("target file already exists: "+rename.path+" -> "+rename.target))
+			}
+			targets[rename.target] = rename
+		}
+	}
+	var ordered []*tRename
+	if len(errs) == 0 {
+		// 0 = not visited, 1 = in progress, 2 = ordered
+		states := make(map[*tRename]int, len(renames))
+		for i := range renames {
+			var err error
+			ordered, err = orderRename(&renames[i], sources, states, ordered)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return ordered, errs
+}
+
+// orderRename appends rename to ordered after the rename of the file
+// occupying its target.
+func orderRename(rename *tRename, sources map[string]*tRename, states map[*tRename]int, ordered []*tRename) ([]*tRename, error) {
+	var err error
+	if rename.target == rename.path {
+		states[rename] = 2
+	} else if states[rename] == 1 {
+		err = errors.New("rename cycle: " + rename.path + " -> " + rename.target)
+	} else if states[rename] == 0 {
+		states[rename] = 1
+		if occupant := sources[rename.target]; occupant != nil {
+			if occupant.target == occupant.path {
+				err = errors.New("target file already exists: " + rename.path + " -> " + rename.target)
+			} else {
+				ordered, err = orderRename(occupant, sources, states, ordered)
+			}
+		}
+		states[rename] = 2
+		if err == nil {
+			ordered = append(ordered, rename)
+		}
+	}
+	return ordered, err
+}
+
+// isSameFileOrFree returns true, if target doesn't exist or is the same file as
+// path, e.g. on case-insensitive file systems.
+func isSameFileOrFree(path, target string) bool {
+	targetInfo, err := os.Lstat(target)
+	if err == nil {
+		info, errInfo := os.Lstat(path)
+		return errInfo == nil && os.SameFile(info, targetInfo)
+	}
+	return os.IsNotExist(err)
+}
+
+// counterValue returns counter for field n or n:WIDTH, which is padded with zeros.
+func counterValue(field string, counter int) string {
+	str := strconv.Itoa(counter)
+	if strings.HasPrefix(field, "n:") {
+		width, _ := strconv.Atoi(field[2:])
+		if len(str) < width {
+			str = strings.Repeat("0", width-len(str)) + str
+		}
+	}
+	return str
+}
+
+func isDigits(str string) bool {
+	for _, b := range []byte(str) {
+		if b < '0' || b > '9' {
+			return false
+		}
+	}
+	return len(str) > 0
+}
@@ -0,0 +1,74 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRename(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "scan-1.pdf"), []byte("INV-123456 INV-654321"), 0666)
+	os.WriteFile(filepath.Join(dir, "scan-2.pdf"), []byte("INV-777777"), 0666)
+	os.WriteFile(filepath.Join(dir, "scan-3.txt"), []byte("INV-888888"), 0666)
+	runTestCommand(t, true, "rename", "-x", dir+"/scan-*.pdf", "--to={n:2}-{1}-{c1}.{ext}", "INV-([0-9]{6})")
+	for _, name := range []string{"01-1-123456.pdf", "02-2-777777.pdf", "scan-3.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Error(err.Error())
+		}
+	}
+}
+
+func TestRenameConflicts(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b", "c"} {
+		os.WriteFile(filepath.Join(dir, name+".txt"), []byte(name), 0666)
+	}
+	// chain a -> b -> c -> d is renamed beginning at the end
+	renames := make([]tRename, 0, 3)
+	for _, name := range []string{"a", "b", "c"} {
+		renames = append(renames, tRename{path: filepath.Join(dir, name+".txt"), values: map[string]string{"name": name}})
+	}
+	template, _ := newTemplate("{next}.txt", func(field string) bool { return field == "next" })
+	next := map[string]string{"a": "b", "b": "c", "c": "d"}
+	for i := range renames {
+		renames[i].values["next"] = next[renames[i].values["name"]]
+	}
+	ordered, errs := planRenames(renames, template)
+	if len(errs) > 0 {
+		t.Error(errs)
+	} else if len(ordered) != 3 || filepath.Base(ordered[0].path) != "c.txt" || filepath.Base(ordered[2].path) != "a.txt" {
+		t.Error(ordered)
+	}
+	// cycle
+	next["c"] = "a"
+	for i := range renames {
+		renames[i].values["next"] = next[renames[i].values["name"]]
+	}
+	if _, errs = planRenames(renames, template); len(errs) != 1 {
+		t.Error(errs)
+	}
+	// collision with file, that is not renamed
+	if _, errs = planRenames(renames[:2], template); len(errs) != 1 {
+		t.Error(errs)
+	}
+	// target is file, that is unchanged
+	renames[1].values["next"] = "c"
+	renames[2].values["next"] = "c"
+	if _, errs = planRenames(renames[1:], template); len(errs) != 1 {
+		t.Error(errs)
+	}
+	// same target
+	renames[0].values["next"] = "d"
+	renames[1].values["next"] = "d"
+	if _, errs = planRenames(renames[:2], template); len(errs) != 1 {
+		t.Error(errs)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// backupSuffixDEFAULT is appended to backups, if --backup has no value.
+const backupSuffixDEFAULT = ".bak"
+
+// tReplacer substitutes matches of --find with --with.
+type tReplacer struct {
+	regex *regexp.Regexp
+	with  []byte
+	// literal is true, if with has no capture references
+	literal bool
+}
+
+// newReplacer compiles find like filter terms, i.e. as regular expression
+// with -x and ignoring case with -i.
+func newReplacer(params *tParameters) (*tReplacer, error) {
+	var term tTerm
+	var err error
+	find := params.find.Values[0]
+	replacer := new(tReplacer)
+	replacer.with = []byte(params.with.Values[0])
+	replacer.literal = !params.regex.Available()
+	if len(find) == 0 {
+		err = errors.New("find pattern is empty")
+	} else if params.regex.Available() && params.ignoreCase.Available() {
+		term, err = newRegexTerm("(?i)" + find)
+	} else if params.regex.Available() {
+		term, err = newRegexTerm(find)
+	} else if params.ignoreCase.Available() {
+		term, err = newRegexTerm("(?i)" + regexp.QuoteMeta(find))
+	} else {
+		term, err = newRegexTerm(regexp.QuoteMeta(find))
+	}
+	if err == nil {
+		replacer.regex = term.regex
+		return replacer, nil
+	}
+	return nil, err
+}
+
+// replace returns data with all matches replaced and number of matches.
+func (replacer *tReplacer) replace(data []byte) ([]byte, int) {
+	matches := replacer.regex.FindAllSubmatchIndex(data, -1)
+	if len(matches) > 0 {
+		var last int
+		replaced := make([]byte, 0, len(data))
+		for _, match := range matches {
+			replaced = append(replaced, data[last:match[0]]...)
+			if replacer.literal {
+				replaced = append(replaced, replacer.with...)
+			} else {
+				replaced = replacer.regex.Expand(replaced, replacer.with, data, match)
+			}
+			last = match[1]
+		}
+		return append(replaced, data[last:]...), len(matches)
+	}
+	return data, 0
+}
+
+// writeAtomic writes data to a temporary file, sets attributes of file info at
+// source and renames temporary file to path.
+func writeAtomic(path, source string, info os.FileInfo, data []byte, preserve int) error {
+	tempPath, err := writeTemp(filepath.Dir(path), bytes.NewReader(data))
+	if err == nil {
+		err = applyMetadata(tempPath, source, info, preserve)
+		if err == nil {
+			err = commitTemp(tempPath, path)
+		} else {
+			os.Remove(tempPath)
+		}
+	}
+	return err
+}
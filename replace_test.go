@@ -0,0 +1,58 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReplace(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "v1.0 and v2.0\nalice\n", "b.txt": "v3.0\n"})
+	path := filepath.Join(dir, "a.txt")
+	modTime := time.Date(2025, 3, 7, 12, 0, 0, 0, time.Local)
+	os.Chmod(path, 0640)
+	os.Chtimes(path, modTime, modTime)
+	runTestCommand(t, false, "replace", "-x", "-p=timestamps", "--backup", "--find=v([0-9]+)\\.0", "--with=v${1}.1", dir+"/*.txt", "alice")
+	if content, err := os.ReadFile(path); err != nil {
+		t.Error(err.Error())
+	} else if string(content) != "v1.1 and v2.1\nalice\n" {
+		t.Error(string(content))
+	}
+	if info, err := os.Stat(path); err == nil && (info.Mode().Perm() != 0640 || !info.ModTime().Equal(modTime)) {
+		t.Error(info.Mode(), info.ModTime())
+	}
+	if content, _ := os.ReadFile(path + ".bak"); string(content) != "v1.0 and v2.0\nalice\n" {
+		t.Error(string(content))
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "b.txt")); string(content) != "v3.0\n" {
+		t.Error(string(content))
+	}
+	// UTF-16 content is matched decoded, but not replaced
+	utf16 := []byte{0xFF, 0xFE, 'a', 0, 'l', 0, 'i', 0, 'c', 0, 'e', 0}
+	os.WriteFile(filepath.Join(dir, "c.txt"), utf16, 0666)
+	runTestCommand(t, false, "replace", "-s", "--find=alice", "--with=bob", dir+"/c*.txt", "alice")
+	if content, _ := os.ReadFile(filepath.Join(dir, "c.txt")); string(content) != string(utf16) {
+		t.Error(content)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12"
+	b := "1\n2\nx\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+	expected := "--- a\n+++ b\n@@ -1,6 +1,6 @@\n 1\n 2\n-3\n+x\n 4\n 5\n 6\n@@ -9,4 +9,5 @@\n 9\n 10\n 11\n-12\n\\ No newline at end of file\n+12\n+13\n"
+	if diff := unifiedDiff("a", "b", []byte(a), []byte(b)); diff != expected {
+		t.Error(diff)
+	}
+	if diff := unifiedDiff("a", "b", []byte(a), []byte(a)); len(diff) > 0 {
+		t.Error(diff)
+	}
+}
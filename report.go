@@ -0,0 +1,126 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// tTermCount is the number of occurrences of a term in content and the
+// offset of the first one.
+type tTermCount struct {
+	count int
+	first int64
+}
+
+// tTermTotal summarises occurrences of a term in all reported files.
+type tTermTotal struct {
+	files       int
+	occurrences int
+}
+
+// countTerms returns occurrences of all terms in content. Content is read in
+// chunks like in matchReader; matches in the overlap of two chunks are
+// counted once.
+func (filter *tContentFilter) countTerms(reader io.Reader, buffer []byte) ([]tTermCount, error) {
+	var carry int
+	var base int64
+	counts := make([]tTermCount, len(filter.terms))
+	// matches beginning before skip are counted already
+	skip := make([]int, len(filter.terms))
+	overlap := filter.overlap
+	if overlap < 0 || overlap > len(buffer)/4 {
+		overlap = len(buffer) / 4
+	}
+	for {
+		n, err := io.ReadFull(reader, buffer[carry:])
+		if err == nil || err == io.EOF || err == io.ErrUnexpectedEOF {
+			window := buffer[:carry+n]
+			// matches beginning in overlap are counted with next chunk
+			limit := len(window) - overlap
+			if err != nil {
+				limit = len(window)
+			}
+			for i := range filter.terms {
+				for _, match := range filter.terms[i].indexAll(window) {
+					if match[0] >= skip[i] && match[0] < limit {
+						if counts[i].count == 0 {
+							counts[i].first = base + int64(match[0])
+						}
+						counts[i].count++
+						skip[i] = match[1]
+					}
+				}
+			}
+			if err != nil {
+				return counts, nil
+			}
+			carry = overlap
+			shift := len(window) - carry
+			for i := range skip {
+				skip[i] = maxInt(skip[i]-shift, 0)
+			}
+			base += int64(shift)
+			copy(buffer, window[shift:])
+		} else {
+			return nil, err
+		}
+	}
+}
+
+// isCountMatch returns true, if counted terms pass the filter expression.
+func (filter *tContentFilter) isCountMatch(counts []tTermCount) bool {
+	match, _ := filter.expr.eval(foundTerms(counts), true)
+	return match
+}
+
+// foundTerms returns true for every counted term.
+func foundTerms(counts []tTermCount) []bool {
+	found := make([]bool, len(counts))
+	for i, count := range counts {
+		found[i] = count.count > 0
+	}
+	return found
+}
+
+// formatTermCounts returns found terms of file, e.g.
+// "a.txt: alice (3, first at 10), bob (1, first at 52)".
+func formatTermCounts(name string, names []string, counts []tTermCount) string {
+	var found []string
+	for i, count := range counts {
+		if count.count > 0 {
+			found = append(found, names[i]+" ("+strconv.Itoa(count.count)+", first at "+strconv.FormatInt(count.first, 10)+")")
+		}
+	}
+	return name + ": " + strings.Join(found, ", ") + "\n"
+}
+
+// formatTermTotals returns table of files and occurrences per term.
+func formatTermTotals(names []string, totals []tTermTotal) string {
+	rows := [][]string{{"TERM", "FILES", "OCCURRENCES"}}
+	for i, total := range totals {
+		rows = append(rows, []string{names[i], strconv.Itoa(total.files), strconv.Itoa(total.occurrences)})
+	}
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = maxInt(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	var table strings.Builder
+	for _, row := range rows {
+		for i, cell := range row[:len(row)-1] {
+			table.WriteString(cell + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)+2))
+		}
+		table.WriteString(row[len(row)-1] + "\n")
+	}
+	return table.String()
+}
@@ -0,0 +1,37 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCountTerms(t *testing.T) {
+	regexTerm, _ := newRegexTerm("b+o")
+	filter := &tContentFilter{terms: []tTerm{newLiteralTerm("alice"), newLiteralTerm("aa"), regexTerm}}
+	filter.expr = newExprAll(len(filter.terms), true)
+	filter.overlap = filter.maxWidth() - 1
+	content := strings.Repeat("xx alice aaaaa bbo ", 7)
+	// buffer is small, so that matches cross chunk boundaries
+	for size := 20; size < 48; size++ {
+		counts, err := filter.countTerms(strings.NewReader(content), make([]byte, size))
+		if err != nil {
+			t.Error(err.Error())
+		} else if counts[0].count != 7 || counts[0].first != 3 || counts[1].count != 14 || counts[1].first != 9 || counts[2].count != 7 || counts[2].first != 15 {
+			t.Error(size, counts)
+		}
+	}
+}
+
+func TestFormatTermTotals(t *testing.T) {
+	table := formatTermTotals([]string{"alice", "bob"}, []tTermTotal{{2, 5}, {0, 0}})
+	if table != "TERM   FILES  OCCURRENCES\nalice  2      5\nbob    0      0\n" {
+		t.Error(table)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Files are written to temporary files first and renamed afterwards, so that
+// a file name appears only with complete content.
+const (
+	tempFilePrefix = ".fbc-"
+	tempFileSuffix = ".tmp"
+)
+
+// staleTempFileAge is the time since last modification, after which temporary
+// files are considered left by an interrupted run. Files of running processes
+// are modified continuously.
+const staleTempFileAge = time.Hour
+
+// tempFileCounter makes names of temporary files unique.
+var tempFileCounter int64
+
+// writeTemp writes content of reader to a new temporary file in directory dir
+// and syncs it. Returns path of temporary file.
+func writeTemp(dir string, reader io.Reader) (string, error) {
+	tempFile, err := createTemp(dir)
+	if err == nil {
+		_, err = io.Copy(tempFile, reader)
+		if err == nil {
+			err = tempFile.Sync()
+		}
+		errClose := tempFile.Close()
+		if err == nil {
+			err = errClose
+		}
+		if err == nil {
+			return tempFile.Name(), nil
+		}
+		os.Remove(tempFile.Name())
+	}
+	return "", err
+}
+
+// createTemp creates a new temporary file in directory dir. Unlike os.CreateTemp
+// permissions are 0666 (before umask), like of files created by os.Create.
+func createTemp(dir string) (*os.File, error) {
+	for {
+		counter := atomic.AddInt64(&tempFileCounter, 1)
+		name := tempFilePrefix + strconv.FormatInt(time.Now().UnixNano()+counter, 36) + tempFileSuffix
+		file, err := os.OpenFile(filepath.Join(dir, name), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if err == nil || !os.IsExist(err) {
+			return file, err
+		}
+	}
+}
+
+// commitTemp renames temporary file to path. Temporary file is removed on error.
+func commitTemp(tempPath, path string) error {
+	err := os.Rename(tempPath, path)
+	if err == nil {
+		// not supported on all systems
+		syncDir(filepath.Dir(path))
+	} else {
+		os.Remove(tempPath)
+	}
+	return err
+}
+
+// syncDir syncs directory entries to disk.
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err == nil {
+		err = file.Sync()
+		file.Close()
+	}
+	return err
+}
+
+func isTempFile(name string) bool {
+	return strings.HasPrefix(name, tempFilePrefix) && strings.HasSuffix(name, tempFileSuffix)
+}
+
+// removeTempFiles removes temporary files left in dir by an interrupted run.
+// Recently modified temporary files may belong to another run and are kept.
+func removeTempFiles(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err == nil {
+		for _, entry := range entries {
+			if entry.Type().IsRegular() && isTempFile(entry.Name()) {
+				info, err := entry.Info()
+				if err == nil && time.Since(info.ModTime()) > staleTempFileAge {
+					os.Remove(filepath.Join(dir, entry.Name()))
+				}
+			}
+		}
+	}
+}
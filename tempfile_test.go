@@ -0,0 +1,34 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAtomicCopy(t *testing.T) {
+	_, inputDir, outputDir := newTestDirs(t, map[string]string{"in/a.txt": "alice", "out/.fbc-123.tmp": "al", "out/.fbc-456.tmp": "al"})
+	// left by interrupted run
+	modTime := time.Now().Add(-2 * staleTempFileAge)
+	os.Chtimes(filepath.Join(outputDir, ".fbc-123.tmp"), modTime, modTime)
+	runTestCommand(t, false, "cp", inputDir, outputDir, "alice")
+	// .fbc-456.tmp may belong to another run
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		t.Error(err.Error())
+	} else if len(entries) != 2 || entries[0].Name() != ".fbc-456.tmp" || entries[1].Name() != "a.txt" {
+		t.Error(entries)
+	}
+	content, err := os.ReadFile(filepath.Join(outputDir, "a.txt"))
+	if err != nil || string(content) != "alice" {
+		t.Error("file not copied")
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// destFields are fields of --dest-template.
+var destFields = []string{"name", "base", "ext", "dir", "year", "month", "day"}
+
+// tTemplate is a path with fields in braces, e.g. {year}/{ext}/{name}.
+// Literal braces are written {{ and }}.
+type tTemplate struct {
+	parts []tTemplatePart
+}
+
+// tTemplatePart is either literal or field.
+type tTemplatePart struct {
+	literal string
+	field   string
+}
+
+// newTemplate parses template. isField returns true for valid field names.
+func newTemplate(str string, isField func(field string) bool) (*tTemplate, error) {
+	template := new(tTemplate)
+	var literal []byte
+	for i := 0; i < len(str); i++ {
+		if str[i] == '{' && i+1 < len(str) && str[i+1] == '{' || str[i] == '}' && i+1 < len(str) && str[i+1] == '}' {
+			literal = append(literal, str[i])
+			i++
+		} else if str[i] == '{' {
+			end := strings.IndexByte(str[i:], '}')
+			if end < 0 {
+				return nil, errors.New("wrong template " + str + ": missing }")
+			}
+			field := str[i+1 : i+end]
+			if !isField(field) {
+				return nil, errors.New("wrong template " + str + ": unknown field {" + field + "}")
+			}
+			if len(literal) > 0 {
+				template.parts = append(template.parts, tTemplatePart{literal: string(literal)})
+				literal = literal[:0]
+			}
+			template.parts = append(template.parts, tTemplatePart{field: field})
+			i += end
+		} else if str[i] == '}' {
+			return nil, errors.New("wrong template " + str + ": missing {")
+		} else {
+			literal = append(literal, str[i])
+		}
+	}
+	if len(literal) > 0 {
+		template.parts = append(template.parts, tTemplatePart{literal: string(literal)})
+	}
+	return template, nil
+}
+
+// newDestTemplate parses template of destination paths. Template ending with
+// slash gets file name appended.
+func newDestTemplate(str string) (*tTemplate, error) {
+	if strings.HasSuffix(str, "/") || strings.HasSuffix(str, string(filepath.Separator)) {
+		str += "{name}"
+	}
+	return newTemplate(str, func(field string) bool {
+		return containsString(destFields, field)
+	})
+}
+
+// expand returns template with fields replaced by values.
+func (template *tTemplate) expand(values map[string]string) string {
+	var expanded strings.Builder
+	for _, part := range template.parts {
+		if len(part.field) > 0 {
+			expanded.WriteString(values[part.field])
+		} else {
+			expanded.WriteString(part.literal)
+		}
+	}
+	return expanded.String()
+}
+
+// fileFields returns values of destination fields for file info. subDir is
+// the directory of file relative to input directory.
+func fileFields(info os.FileInfo, subDir string) map[string]string {
+	values := make(map[string]string, len(destFields))
+	name := info.Name()
+	ext := path.Ext(name)
+	if ext == name {
+		ext = ""
+	}
+	modTime := info.ModTime()
+	values["name"] = name
+	values["base"] = name[:len(name)-len(ext)]
+	values["ext"] = strings.TrimPrefix(ext, ".")
+	values["dir"] = strings.Trim(filepath.ToSlash(subDir), "/")
+	values["year"] = modTime.Format("2006")
+	values["month"] = modTime.Format("01")
+	values["day"] = modTime.Format("02")
+	return values
+}
+
+// relativePath returns expanded template as path relative to output directory.
+func relativePath(expanded string) (string, error) {
+	relPath := filepath.Clean(filepath.FromSlash(expanded))
+	if filepath.IsAbs(relPath) || relPath == "." || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) || len(filepath.VolumeName(relPath)) > 0 {
+		return "", errors.New("destination outside of output directory: " + expanded)
+	}
+	return relPath, nil
+}
+
+func containsString(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDestTemplate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	os.WriteFile(path, []byte("alice"), 0666)
+	modTime := time.Date(2025, 3, 7, 12, 0, 0, 0, time.Local)
+	os.Chtimes(path, modTime, modTime)
+	info, _ := os.Stat(path)
+	values := fileFields(info, filepath.Join("docs", "x")+string(filepath.Separator))
+	for str, expected := range map[string]string{
+		"{year}/{month}/{ext}/":    "2025/03/txt/a.txt",
+		"{dir}/{base}-{day}.{ext}": "docs/x/a-07.txt",
+		"{{{name}}}":               "{a.txt}",
+	} {
+		template, err := newDestTemplate(str)
+		if err != nil {
+			t.Error(err.Error())
+		} else if expanded := template.expand(values); expanded != expected {
+			t.Error(str, expanded)
+		}
+	}
+	for _, str := range []string{"{size}/{name}", "{name", "name}"} {
+		if _, err := newDestTemplate(str); err == nil {
+			t.Error(str)
+		}
+	}
+	for _, str := range []string{"../a.txt", "/a.txt", "x/../../a.txt"} {
+		if _, err := relativePath(str); err == nil {
+			t.Error(str)
+		}
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	_, inputDir, outputDir := newTestDirs(t, map[string]string{"in/a.txt": "alice", "in/sub/a.txt": "alice", "in/sub/b.txt": "alice", "out/b.txt": "bob"})
+	runTestCommand(t, false, "cp", "-r", "--flatten", inputDir, outputDir, "alice")
+	for _, name := range []string{"a.txt", "a (1).txt"} {
+		if content, err := os.ReadFile(filepath.Join(outputDir, name)); err != nil {
+			t.Error(err.Error())
+		} else if string(content) != "alice" {
+			t.Error(name, string(content))
+		}
+	}
+	// existing targets follow overwrite policy
+	if content, _ := os.ReadFile(filepath.Join(outputDir, "b.txt")); string(content) != "bob" {
+		t.Error(string(content))
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "sub")); err == nil {
+		t.Error("subdirectory created")
+	}
+	// existing target is overwritten once, other files get numbered names
+	_, inputDir, outputDir = newTestDirs(t, map[string]string{"in/x/a.txt": "alice x", "in/y/a.txt": "alice y", "out/a.txt": "old"})
+	runTestCommand(t, false, "mv", "-r", "--flatten", "--overwrite=always", inputDir, outputDir, "alice")
+	for name, expected := range map[string]string{"a.txt": "alice x", "a (1).txt": "alice y"} {
+		if content, err := os.ReadFile(filepath.Join(outputDir, name)); err != nil {
+			t.Error(err.Error())
+		} else if string(content) != expected {
+			t.Error(name, string(content))
+		}
+	}
+	// repeated run overwrites the same targets
+	_, inputDir, outputDir = newTestDirs(t, map[string]string{"in/x/a.txt": "alice x", "in/y/a.txt": "alice y", "in/z/a.txt": "alice z"})
+	for i := 0; i < 2; i++ {
+		runTestCommand(t, false, "cp", "-r", "-t", "--flatten", "--overwrite=always", inputDir, outputDir, "alice")
+		if entries, err := os.ReadDir(outputDir); err != nil {
+			t.Error(err.Error())
+		} else if len(entries) != 3 {
+			t.Error(i, entries)
+		}
+	}
+	for name, expected := range map[string]string{"a.txt": "alice x", "a (1).txt": "alice y", "a (2).txt": "alice z"} {
+		if content, err := os.ReadFile(filepath.Join(outputDir, name)); err != nil {
+			t.Error(err.Error())
+		} else if string(content) != expected {
+			t.Error(name, string(content))
+		}
+	}
+}
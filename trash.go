@@ -0,0 +1,200 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// trashEnv is the environment variable, that makes trash default for rm.
+const trashEnv = "FBC_TRASH"
+
+// tTrash moves files into trash as described in the freedesktop.org Trash
+// specification. Files on other devices than home trash are moved into
+// trash in top directory of their device.
+type tTrash struct {
+	homeDir   string
+	homeDev   uint64
+	topDirs   map[uint64]tTrashDir
+	mutex     sync.Mutex
+	uidString string
+}
+
+// tTrashDir is a trash directory with subdirectories files and info.
+type tTrashDir struct {
+	path string
+	// topDir is the mount point, if paths in trash info are relative
+	topDir string
+}
+
+// isTrashDefault returns true, if environment variable FBC_TRASH is set to 1, true or yes.
+func isTrashDefault() bool {
+	value := strings.ToLower(os.Getenv(trashEnv))
+	return value == "1" || value == "true" || value == "yes"
+}
+
+func newTrash() (*tTrash, error) {
+	var err error
+	trash := new(tTrash)
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if len(dataDir) == 0 {
+		var homeDir string
+		homeDir, err = os.UserHomeDir()
+		dataDir = filepath.Join(homeDir, ".local", "share")
+	}
+	if err == nil {
+		trash.homeDir = filepath.Join(dataDir, "Trash")
+		err = ensureTrashDir(trash.homeDir)
+		if err == nil {
+			trash.homeDev, err = fileDevice(trash.homeDir)
+			trash.topDirs = make(map[uint64]tTrashDir)
+			trash.uidString = strconv.Itoa(os.Getuid())
+		}
+	}
+	if err != nil {
+		return nil, errors.New("can't access trash: " + err.Error())
+	}
+	return trash, nil
+}
+
+// move moves file into trash and returns its new path.
+func (trash *tTrash) move(path string) (string, error) {
+	trashDir, err := trash.trashDir(path)
+	if err == nil {
+		var name string
+		name, err = createTrashInfo(trashDir, path, time.Now())
+		if err == nil {
+			trashPath := filepath.Join(trashDir.path, "files", name)
+			err = os.Rename(path, trashPath)
+			if err == nil {
+				return trashPath, nil
+			}
+			os.Remove(trashInfoPath(trashDir.path, name))
+		}
+	}
+	return "", err
+}
+
+// trashDir returns trash on the same device as file path.
+func (trash *tTrash) trashDir(path string) (tTrashDir, error) {
+	dev, err := fileDevice(path)
+	if err == nil {
+		if dev == trash.homeDev {
+			return tTrashDir{path: trash.homeDir}, nil
+		}
+		trash.mutex.Lock()
+		defer trash.mutex.Unlock()
+		trashDir, ok := trash.topDirs[dev]
+		if !ok {
+			trashDir.topDir, err = mountPoint(path)
+			if err == nil {
+				trashDir.path, err = trash.topTrashDir(trashDir.topDir)
+				if err == nil {
+					trash.topDirs[dev] = trashDir
+				}
+			}
+		}
+		return trashDir, err
+	}
+	return tTrashDir{}, err
+}
+
+// topTrashDir returns $topdir/.Trash/$uid, if $topdir/.Trash is a directory
+// with sticky bit set (and not a symbolic link), otherwise $topdir/.Trash-$uid.
+func (trash *tTrash) topTrashDir(topDir string) (string, error) {
+	sharedDir := filepath.Join(topDir, ".Trash")
+	info, err := os.Lstat(sharedDir)
+	if err == nil && info.IsDir() && info.Mode()&os.ModeSticky != 0 {
+		dir := filepath.Join(sharedDir, trash.uidString)
+		if ensureTrashDir(dir) == nil {
+			return dir, nil
+		}
+	}
+	dir := filepath.Join(topDir, ".Trash-"+trash.uidString)
+	return dir, ensureTrashDir(dir)
+}
+
+func ensureTrashDir(dir string) error {
+	err := os.MkdirAll(filepath.Join(dir, "files"), 0700)
+	if err == nil {
+		err = os.MkdirAll(filepath.Join(dir, "info"), 0700)
+	}
+	return err
+}
+
+// createTrashInfo reserves a name in trash by creating its .trashinfo file.
+// On name collision a number is inserted before extension, e.g. a.2.txt.
+func createTrashInfo(trashDir tTrashDir, path string, now time.Time) (string, error) {
+	name := filepath.Base(path)
+	ext := filepath.Ext(name)
+	if ext == name {
+		ext = ""
+	}
+	content := "[Trash Info]\nPath=" + trashInfoPathValue(trashDir.topDir, path) + "\nDeletionDate=" + now.Format("2006-01-02T15:04:05") + "\n"
+	for i := 1; ; i++ {
+		candidate := name
+		if i > 1 {
+			candidate = name[:len(name)-len(ext)] + "." + strconv.Itoa(i) + ext
+		}
+		infoPath := trashInfoPath(trashDir.path, candidate)
+		infoFile, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			_, err = infoFile.WriteString(content)
+			errClose := infoFile.Close()
+			if err == nil {
+				err = errClose
+			}
+			// file may be in trash without info file
+			_, errStat := os.Lstat(filepath.Join(trashDir.path, "files", candidate))
+			if err == nil && errStat != nil {
+				return candidate, nil
+			}
+			os.Remove(infoPath)
+			if err != nil {
+				return "", err
+			}
+		} else if !os.IsExist(err) {
+			return "", err
+		}
+	}
+}
+
+func trashInfoPath(trashDir, name string) string {
+	return filepath.Join(trashDir, "info", name+".trashinfo")
+}
+
+// trashInfoPathValue returns URL escaped path, relative to topDir, if topDir is not empty.
+func trashInfoPathValue(topDir, path string) string {
+	if len(topDir) > 0 {
+		relPath, err := filepath.Rel(topDir, path)
+		if err == nil {
+			path = relPath
+		}
+	}
+	value := &url.URL{Path: filepath.ToSlash(path)}
+	return value.EscapedPath()
+}
+
+// removeTrashInfo removes .trashinfo of file at trashPath, if file was in
+// trash and has been restored.
+func removeTrashInfo(trashPath string) {
+	filesDir := filepath.Dir(trashPath)
+	if filepath.Base(filesDir) == "files" {
+		infoPath := trashInfoPath(filepath.Dir(filesDir), filepath.Base(trashPath))
+		if _, err := os.Lstat(infoPath); err == nil {
+			os.Remove(infoPath)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTrash(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := fileDevice(dir); err != nil {
+		t.Skip(err.Error())
+	}
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	inputDir := filepath.Join(dir, "in dir")
+	journalPath := filepath.Join(dir, "fbc.journal")
+	os.MkdirAll(filepath.Join(inputDir, "sub"), 0777)
+	os.WriteFile(filepath.Join(inputDir, "a.txt"), []byte("alice"), 0666)
+	os.WriteFile(filepath.Join(inputDir, "sub", "a.txt"), []byte("alice"), 0666)
+	runTestCommand(t, false, "rm", "-r", "--trash", "--journal="+journalPath, inputDir, "alice")
+	trashDir := filepath.Join(dir, "data", "Trash")
+	for _, name := range []string{"a.txt", "a.2.txt"} {
+		if _, err := os.Stat(filepath.Join(trashDir, "files", name)); err != nil {
+			t.Error(err.Error())
+		}
+	}
+	info, err := os.ReadFile(filepath.Join(trashDir, "info", "a.txt.trashinfo"))
+	if err != nil {
+		t.Error(err.Error())
+	} else if !strings.HasPrefix(string(info), "[Trash Info]\nPath=") || !strings.Contains(string(info), "/in%20dir/") || !strings.Contains(string(info), "\nDeletionDate=") {
+		t.Error(string(info))
+	}
+	count, err := undoJournal(journalPath, false, true)
+	if err != nil {
+		t.Error(err.Error())
+	} else if count != 2 {
+		t.Error(count)
+	}
+	if _, err := os.Stat(filepath.Join(trashDir, "info", "a.2.txt.trashinfo")); err == nil {
+		t.Error("trash info of restored file not removed")
+	}
+}
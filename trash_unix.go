@@ -0,0 +1,52 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+func fileDevice(path string) (uint64, error) {
+	info, err := os.Lstat(path)
+	if err == nil {
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if ok {
+			return uint64(stat.Dev), nil
+		}
+		err = errors.New("device unknown: " + path)
+	}
+	return 0, err
+}
+
+// mountPoint returns the top directory of the device containing path.
+func mountPoint(path string) (string, error) {
+	dev, err := fileDevice(path)
+	if err == nil {
+		dir := path
+		for {
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				return dir, nil
+			}
+			parentDev, err := fileDevice(parent)
+			if err != nil {
+				return "", err
+			} else if parentDev != dev {
+				return dir, nil
+			}
+			dir = parent
+		}
+	}
+	return "", err
+}
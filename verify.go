@@ -0,0 +1,135 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	hashSHA256 = iota
+	hashBLAKE2B
+	hashXXH64
+)
+
+// tVerifier compares checksums of copied files with checksums of their
+// sources and collects them for manifest.
+type tVerifier struct {
+	algorithm  int
+	outputDir  string
+	manifest   bool
+	entries    []tManifestEntry
+	mismatches int
+	mutex      sync.Mutex
+}
+
+// tManifestEntry is a line in manifest.
+type tManifestEntry struct {
+	sum  string
+	path string
+}
+
+// parseHashAlgorithm parses sha256 (or empty string), blake2b (BLAKE2b-512) or xxh64.
+func parseHashAlgorithm(str string) (int, error) {
+	switch strings.ToLower(str) {
+	case "", "sha256", "sha-256":
+		return hashSHA256, nil
+	case "blake2", "blake2b", "b2":
+		return hashBLAKE2B, nil
+	case "xxh64", "xxhash":
+		return hashXXH64, nil
+	}
+	return hashSHA256, errors.New("wrong hash algorithm: " + str)
+}
+
+func newVerifier(algorithm int, outputDir string, manifest bool) *tVerifier {
+	verifier := new(tVerifier)
+	verifier.algorithm = algorithm
+	verifier.outputDir = outputDir
+	verifier.manifest = manifest
+	return verifier
+}
+
+func (verifier *tVerifier) newHash() hash.Hash {
+	switch verifier.algorithm {
+	case hashBLAKE2B:
+		return newBLAKE2b()
+	case hashXXH64:
+		return newXXH64()
+	}
+	return sha256.New()
+}
+
+// manifestName returns file name of manifest, as used by sha256sum, b2sum and xxhsum.
+func (verifier *tVerifier) manifestName() string {
+	switch verifier.algorithm {
+	case hashBLAKE2B:
+		return "B2SUMS"
+	case hashXXH64:
+		return "XXH64SUMS"
+	}
+	return "SHA256SUMS"
+}
+
+// hashFile returns checksum of file at path in hex.
+func (verifier *tVerifier) hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err == nil {
+		defer file.Close()
+		digest := verifier.newHash()
+		_, err = io.Copy(digest, file)
+		if err == nil {
+			return hex.EncodeToString(digest.Sum(nil)), nil
+		}
+	}
+	return "", err
+}
+
+// verify compares checksum of file at path with sum of its source.
+func (verifier *tVerifier) verify(path, sum string) error {
+	pathSum, err := verifier.hashFile(path)
+	if err == nil {
+		verifier.mutex.Lock()
+		defer verifier.mutex.Unlock()
+		if pathSum == sum {
+			if verifier.manifest {
+				relPath, _ := filepath.Rel(verifier.outputDir, path)
+				verifier.entries = append(verifier.entries, tManifestEntry{sum, filepath.ToSlash(relPath)})
+			}
+		} else {
+			verifier.mismatches++
+			err = errors.New("checksum mismatch: " + path)
+		}
+	}
+	return err
+}
+
+// writeManifest writes checksums of verified files into output directory.
+func (verifier *tVerifier) writeManifest() error {
+	var content strings.Builder
+	sort.Slice(verifier.entries, func(i, j int) bool {
+		return verifier.entries[i].path < verifier.entries[j].path
+	})
+	for _, entry := range verifier.entries {
+		content.WriteString(entry.sum + "  " + entry.path + "\n")
+	}
+	tempPath, err := writeTemp(verifier.outputDir, strings.NewReader(content.String()))
+	if err == nil {
+		err = commitTemp(tempPath, filepath.Join(verifier.outputDir, verifier.manifestName()))
+	}
+	return err
+}
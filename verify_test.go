@@ -0,0 +1,58 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"hash"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHashes(t *testing.T) {
+	var long []byte
+	for i := 0; i < 3; i++ {
+		for k := 0; k < 256; k++ {
+			long = append(long, byte(k))
+		}
+	}
+	messages := [][]byte{[]byte(""), []byte("abc"), bytes.Repeat([]byte("a"), 200), long}
+	blake2bSums := []string{"786a02f742015903c6c6fd852552d272", "ba80a53f981c4d0d6a2797b69f12f6e9", "932355851d75f09c18646a9da87c25e0", "323e97a7a859ee63c9013debb0ca9958"}
+	xxh64Sums := []string{"ef46db3751d8e999", "44bc2cf5ad770999", "942e9189f34eebbe", "8e03c838c596036f"}
+	for i, message := range messages {
+		sum := testSum(newBLAKE2b(), message)
+		if !strings.HasPrefix(sum, blake2bSums[i]) || len(sum) != 128 {
+			t.Error(i, sum)
+		}
+		sum = testSum(newXXH64(), message)
+		if sum != xxh64Sums[i] {
+			t.Error(i, sum)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	_, inputDir, outputDir := newTestDirs(t, map[string]string{"in/sub/a.txt": "alice"})
+	runTestCommand(t, true, "cp", "-r", "--verify", "--manifest", inputDir, outputDir, "alice")
+	manifest, err := os.ReadFile(filepath.Join(outputDir, "SHA256SUMS"))
+	if err != nil {
+		t.Error(err.Error())
+	} else if string(manifest) != "2bd806c97f0e00af1a1fc3328fa763a9269723c8db8fac4f93af71db186d6e90  sub/a.txt\n" {
+		t.Error(string(manifest))
+	}
+}
+
+// testSum writes message in two parts to test buffering.
+func testSum(digest hash.Hash, message []byte) string {
+	digest.Write(message[:len(message)/3])
+	digest.Write(message[len(message)/3:])
+	return hex.EncodeToString(digest.Sum(nil))
+}
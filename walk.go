@@ -0,0 +1,186 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// tWalker iterates over files calling proc.ProcessFile. Subdirectories
+// rejected by proc.isDirMatch or ignore files are skipped with all their content.
+type tWalker struct {
+	proc        tFileProcessor
+	recursive   bool
+	threads     bool
+	ignoreFiles bool
+	ignoreStack tIgnoreStack
+	wg          sync.WaitGroup
+	mutex       sync.Mutex
+	errResult   error
+}
+
+func iterateFiles(params *tParameters, proc tFileProcessor) error {
+	var err error
+	walker := new(tWalker)
+	walker.proc = proc
+	walker.recursive = params.isRecursive()
+	// targets of templates are numbered in order of paths
+	walker.threads = params.threads.Available() && !params.flatten.Available() && !params.destTemplate.Available()
+	walker.ignoreFiles = params.ignoreFiles.Available()
+	dir := params.inputDir()
+	if params.filesFrom.Available() {
+		var paths []string
+		paths, err = readFileList(params.filesFrom.Values[0])
+		if err == nil {
+			err = walker.iterateList(dir, paths)
+		}
+	} else {
+		err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if info != nil {
+				if path == dir {
+					walker.enterDir(path)
+				} else if walker.isIgnored(path, info) {
+					if info.IsDir() {
+						return filepath.SkipDir
+					}
+				} else if !info.IsDir() {
+					return walker.processFile(path, info, err)
+				} else if walker.recursive && proc.isDirMatch(path, info) {
+					walker.enterDir(path)
+				} else {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		})
+	}
+	walker.wg.Wait()
+	if err == nil {
+		return walker.err()
+	}
+	return err
+}
+
+// readFileList returns paths listed in file at path, or in stdin, if path is
+// "-". Paths are separated by NUL, if list contains NUL, otherwise by newline.
+func readFileList(path string) ([]string, error) {
+	var list []byte
+	var err error
+	if path == "-" {
+		list, err = io.ReadAll(os.Stdin)
+	} else {
+		list, err = os.ReadFile(path)
+	}
+	if err == nil {
+		var paths []string
+		separator := "\n"
+		if bytes.IndexByte(list, 0) >= 0 {
+			separator = "\x00"
+		}
+		for _, listedPath := range strings.Split(string(list), separator) {
+			if separator == "\n" {
+				listedPath = strings.TrimSuffix(listedPath, "\r")
+			}
+			if len(listedPath) > 0 {
+				paths = append(paths, listedPath)
+			}
+		}
+		return paths, nil
+	}
+	return nil, err
+}
+
+// iterateList processes listed files instead of walking dir. Relative paths
+// are relative to dir. Directories are skipped; files in directories rejected
+// by proc.isDirMatch, too. Paths outside of dir are errors of single files.
+func (walker *tWalker) iterateList(dir string, paths []string) error {
+	var err error
+	visited := make(map[string]bool, len(paths))
+	dirPrefix := dir[:dirLengthWOEndingSeparator(dir)] + string(filepath.Separator)
+	for i := 0; i < len(paths) && err == nil; i++ {
+		path := filepath.Clean(paths[i])
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if !visited[path] {
+			visited[path] = true
+			if path != dir && !strings.HasPrefix(path, dirPrefix) {
+				err = walker.processFile(path, nil, errors.New("file is not in input directory: "+paths[i]))
+			} else if info, errInfo := os.Lstat(path); errInfo != nil && os.IsNotExist(errInfo) {
+				// walk ignores vanished files, but listed files must exist
+				err = walker.processFile(path, nil, errors.New("file does not exist: "+paths[i]))
+			} else if errInfo != nil {
+				err = walker.processFile(path, nil, errInfo)
+			} else if !info.IsDir() && walker.isParentDirsMatch(dirPrefix, path) {
+				err = walker.processFile(path, info, nil)
+			}
+		}
+	}
+	return err
+}
+
+// isParentDirsMatch returns true, if all directories between dirPrefix and
+// path pass the directory filters.
+func (walker *tWalker) isParentDirsMatch(dirPrefix, path string) bool {
+	for parent := filepath.Dir(path); len(parent) > len(dirPrefix); parent = filepath.Dir(parent) {
+		info, err := os.Stat(parent)
+		if err != nil || !walker.proc.isDirMatch(parent, info) {
+			return false
+		}
+	}
+	return true
+}
+
+func (walker *tWalker) enterDir(dir string) {
+	if walker.ignoreFiles {
+		walker.ignoreStack.enter(dir)
+	}
+}
+
+// isIgnored returns true, if path is excluded by ignore files. Directory .git is
+// always ignored then.
+func (walker *tWalker) isIgnored(path string, info os.FileInfo) bool {
+	if walker.ignoreFiles {
+		walker.ignoreStack.update(path)
+		return info.IsDir() && info.Name() == ".git" || walker.ignoreStack.isIgnored(path, info)
+	}
+	return false
+}
+
+func (walker *tWalker) processFile(path string, info os.FileInfo, err error) error {
+	if walker.threads {
+		walker.wg.Add(1)
+		go walker.processFileGo(path, info, err)
+		return walker.err()
+	}
+	return walker.proc.ProcessFile(path, info, err)
+}
+
+func (walker *tWalker) processFileGo(path string, info os.FileInfo, err error) {
+	err = walker.proc.ProcessFile(path, info, err)
+	if err != nil {
+		walker.mutex.Lock()
+		if walker.errResult == nil {
+			walker.errResult = err
+		}
+		walker.mutex.Unlock()
+	}
+	walker.wg.Done()
+}
+
+func (walker *tWalker) err() error {
+	walker.mutex.Lock()
+	defer walker.mutex.Unlock()
+	return walker.errResult
+}
@@ -0,0 +1,56 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadFileList(t *testing.T) {
+	dir := t.TempDir()
+	listPath := filepath.Join(dir, "list")
+	os.WriteFile(listPath, []byte("a.txt\r\nsub/b c.txt\n\n"), 0666)
+	paths, err := readFileList(listPath)
+	if err != nil {
+		t.Error(err.Error())
+	} else if len(paths) != 2 || paths[0] != "a.txt" || paths[1] != "sub/b c.txt" {
+		t.Error(paths)
+	}
+	os.WriteFile(listPath, []byte("a\nb.txt\x00c.txt\x00"), 0666)
+	paths, err = readFileList(listPath)
+	if err != nil {
+		t.Error(err.Error())
+	} else if len(paths) != 2 || paths[0] != "a\nb.txt" || paths[1] != "c.txt" {
+		t.Error(paths)
+	}
+}
+
+func TestFilesFrom(t *testing.T) {
+	dir, inputDir, _ := newTestDirs(t, map[string]string{
+		"in/a b.txt":    "alice",
+		"in/sub/b.txt":  "alice",
+		"in/skip/c.txt": "alice",
+		"in/d.txt":      "alice",
+		"e.txt":         "alice",
+		"list":          "./a b.txt\x00sub\x00sub/b.txt\x00skip/c.txt\x00../e.txt\x00",
+	})
+	listPath := filepath.Join(dir, "list")
+	runTestCommand(t, false, "rm", "-s", "--no-trash", "--files-from="+listPath, "--exclude-dir=skip", inputDir, "alice")
+	for _, path := range []string{filepath.Join(inputDir, "a b.txt"), filepath.Join(inputDir, "sub", "b.txt")} {
+		if _, err := os.Stat(path); err == nil {
+			t.Error("not removed: " + path)
+		}
+	}
+	for _, path := range []string{filepath.Join(inputDir, "skip", "c.txt"), filepath.Join(inputDir, "d.txt"), filepath.Join(dir, "e.txt")} {
+		if _, err := os.Stat(path); err != nil {
+			t.Error(err.Error())
+		}
+	}
+}
@@ -0,0 +1,110 @@
+/*
+ *          Copyright 2026, Vitali Baumtrok.
+ * Distributed under the Boost Software License, Version 1.0.
+ *     (See accompanying file LICENSE or copy at
+ *        http://www.boost.org/LICENSE_1_0.txt)
+ */
+
+package main
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	xxhPrime1 uint64 = 11400714785074694791
+	xxhPrime2 uint64 = 14029467366897019727
+	xxhPrime3 uint64 = 1609587929392839161
+	xxhPrime4 uint64 = 9650029242287828579
+	xxhPrime5 uint64 = 2870177450012600261
+)
+
+// tXXH64 is XXH64 with seed 0. Sum is big endian, like output of xxhsum.
+type tXXH64 struct {
+	v      [4]uint64
+	total  uint64
+	stripe [32]byte
+	offset int
+}
+
+func newXXH64() *tXXH64 {
+	digest := new(tXXH64)
+	digest.Reset()
+	return digest
+}
+
+func (digest *tXXH64) Reset() {
+	prime1, prime2 := xxhPrime1, xxhPrime2
+	// seed is 0, wrapping arithmetic
+	digest.v = [4]uint64{prime1 + prime2, prime2, 0, -prime1}
+	digest.total = 0
+	digest.offset = 0
+}
+
+func (digest *tXXH64) Size() int {
+	return 8
+}
+
+func (digest *tXXH64) BlockSize() int {
+	return 32
+}
+
+func (digest *tXXH64) Write(data []byte) (int, error) {
+	length := len(data)
+	digest.total += uint64(length)
+	for len(data) > 0 {
+		n := copy(digest.stripe[digest.offset:], data)
+		digest.offset += n
+		data = data[n:]
+		if digest.offset == len(digest.stripe) {
+			for i := range digest.v {
+				digest.v[i] = xxhRound(digest.v[i], binary.LittleEndian.Uint64(digest.stripe[i*8:]))
+			}
+			digest.offset = 0
+		}
+	}
+	return length, nil
+}
+
+func (digest *tXXH64) Sum(b []byte) []byte {
+	var h uint64
+	if digest.total >= 32 {
+		v := digest.v
+		h = bits.RotateLeft64(v[0], 1) + bits.RotateLeft64(v[1], 7) + bits.RotateLeft64(v[2], 12) + bits.RotateLeft64(v[3], 18)
+		for _, lane := range v {
+			h ^= xxhRound(0, lane)
+			h = h*xxhPrime1 + xxhPrime4
+		}
+	} else {
+		h = xxhPrime5
+	}
+	h += digest.total
+	rest := digest.stripe[:digest.offset]
+	for ; len(rest) >= 8; rest = rest[8:] {
+		h ^= xxhRound(0, binary.LittleEndian.Uint64(rest))
+		h = bits.RotateLeft64(h, 27)*xxhPrime1 + xxhPrime4
+	}
+	if len(rest) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(rest)) * xxhPrime1
+		h = bits.RotateLeft64(h, 23)*xxhPrime2 + xxhPrime3
+		rest = rest[4:]
+	}
+	for _, r := range rest {
+		h ^= uint64(r) * xxhPrime5
+		h = bits.RotateLeft64(h, 11) * xxhPrime1
+	}
+	h ^= h >> 33
+	h *= xxhPrime2
+	h ^= h >> 29
+	h *= xxhPrime3
+	h ^= h >> 32
+	var sum [8]byte
+	binary.BigEndian.PutUint64(sum[:], h)
+	return append(b, sum[:]...)
+}
+
+func xxhRound(acc, lane uint64) uint64 {
+	acc += lane * xxhPrime2
+	return bits.RotateLeft64(acc, 31) * xxhPrime1
+}